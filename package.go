@@ -1,17 +1,139 @@
 package analysisutil
 
 import (
+	"os"
+	"slices"
+	"strings"
+
 	"github.com/cockroachdb/errors"
 	"golang.org/x/tools/go/packages"
 )
 
+// DefaultLoadMode is the packages.LoadMode used by LoadPackages.
+const DefaultLoadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+	packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo |
+	packages.NeedDeps | packages.NeedModule
+
+// LoadConfig configures LoadPackagesWithConfig.
+// The zero value loads the packages in the current directory in the same way as LoadPackages.
+type LoadConfig struct {
+	// Dir is the directory in which to run the build system's query tool.
+	Dir string
+	// Mode is the packages.LoadMode. DefaultLoadMode is used if it is zero.
+	Mode packages.LoadMode
+	// Tests includes the test variants of the packages and the test executables.
+	Tests bool
+	// GOOS and GOARCH override the target platform if they are not empty.
+	GOOS   string
+	GOARCH string
+	// BuildTags are passed to the build system as -tags.
+	BuildTags []string
+	// BuildFlags are passed to the build system as-is.
+	BuildFlags []string
+	// Env is the environment of the build system. os.Environ() is used if it is nil.
+	Env []string
+	// Overlay maps absolute file paths to their in-memory contents.
+	Overlay map[string][]byte
+}
+
+func (c *LoadConfig) packagesConfig() *packages.Config {
+	mode := c.Mode
+	if mode == 0 {
+		mode = DefaultLoadMode
+	}
+
+	env := c.Env
+	if c.GOOS != "" || c.GOARCH != "" {
+		if env == nil {
+			env = os.Environ()
+		}
+		env = slices.Clip(env)
+		if c.GOOS != "" {
+			env = append(env, "GOOS="+c.GOOS)
+		}
+		if c.GOARCH != "" {
+			env = append(env, "GOARCH="+c.GOARCH)
+		}
+	}
+
+	flags := slices.Clone(c.BuildFlags)
+	if len(c.BuildTags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(c.BuildTags, ","))
+	}
+
+	return &packages.Config{Mode: mode, Dir: c.Dir, Tests: c.Tests, Env: env, BuildFlags: flags, Overlay: c.Overlay}
+}
+
 // LoadPackages https://github.com/golang/tools/blob/master/go/analysis/analysistest/analysistest.go
 func LoadPackages(dir string, patterns ...string) ([]*packages.Package, error) {
-	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
-		packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo |
-		packages.NeedDeps | packages.NeedModule
-	cfg := &packages.Config{Mode: mode, Dir: dir}
-	pkgs, err := packages.Load(cfg, patterns...)
+	return LoadPackagesWithConfig(&LoadConfig{Dir: dir}, patterns...)
+}
+
+// LoadPackagesWithConfig is LoadPackages with the loader options of cfg.
+func LoadPackagesWithConfig(cfg *LoadConfig, patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	if pkgErrs := collectErrors(pkgs); len(pkgErrs) > 0 {
+		errs := make([]error, 0, len(pkgErrs))
+		for _, e := range pkgErrs {
+			errs = append(errs, packages.Error{Pos: e.Pos, Msg: e.Msg, Kind: e.Kind})
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	return pkgs, nil
+}
+
+// PackageError is an error reported for a single package by LoadPackagesPartial.
+type PackageError struct {
+	PkgPath string
+	Kind    packages.ErrorKind
+	Pos     string // "file:line:col" or "file:line" or "" or "-"
+	Msg     string
+}
+
+func (e *PackageError) Error() string {
+	return e.PkgPath + ": " + packages.Error{Pos: e.Pos, Msg: e.Msg, Kind: e.Kind}.Error()
+}
+
+// KindString returns the name of the error kind such as "list", "parse" or "type".
+func (e *PackageError) KindString() string {
+	switch e.Kind {
+	case packages.ListError:
+		return "list"
+	case packages.ParseError:
+		return "parse"
+	case packages.TypeError:
+		return "type"
+	default:
+		return "unknown"
+	}
+}
+
+// LoadPackagesPartial is LoadPackagesWithConfig that tolerates broken packages.
+// It returns the matched packages that type-checked without errors, including in their dependencies,
+// together with the errors of every package in the dependency graph.
+// The error is only non-nil if loading itself failed.
+func LoadPackagesPartial(cfg *LoadConfig, patterns ...string) ([]*packages.Package, []*PackageError, error) {
+	pkgs, err := load(cfg, patterns...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	healthy := make([]*packages.Package, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 && !pkg.IllTyped {
+			healthy = append(healthy, pkg)
+		}
+	}
+	return healthy, collectErrors(pkgs), nil
+}
+
+func load(cfg *LoadConfig, patterns ...string) ([]*packages.Package, error) {
+	pkgs, err := packages.Load(cfg.packagesConfig(), patterns...)
 	if err != nil {
 		return nil, err
 	}
@@ -19,16 +141,15 @@ func LoadPackages(dir string, patterns ...string) ([]*packages.Package, error) {
 	if len(pkgs) == 0 {
 		return nil, errors.Newf("no packages matched %s", patterns)
 	}
+	return pkgs, nil
+}
 
-	errs := make([]error, 0)
+func collectErrors(pkgs []*packages.Package) []*PackageError {
+	errs := make([]*PackageError, 0)
 	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
 		for _, err := range pkg.Errors {
-			errs = append(errs, err)
+			errs = append(errs, &PackageError{PkgPath: pkg.PkgPath, Kind: err.Kind, Pos: err.Pos, Msg: err.Msg})
 		}
 	})
-	if len(errs) > 0 {
-		return nil, errors.Join(errs...)
-	}
-
-	return pkgs, nil
+	return errs
 }
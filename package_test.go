@@ -0,0 +1,133 @@
+package analysisutil_test
+
+import (
+	"go/constant"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/haijima/analysisutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+const loadDir = "./testdata/src/load"
+
+func constOf(t *testing.T, pkg *packages.Package, name string) constant.Value {
+	t.Helper()
+	require.NotNil(t, pkg.Types)
+	obj := pkg.Types.Scope().Lookup(name)
+	require.NotNil(t, obj, name)
+	c, ok := obj.(*types.Const)
+	require.True(t, ok, name)
+	return c.Val()
+}
+
+func TestLoadPackages(t *testing.T) {
+	pkgs, err := analysisutil.LoadPackages(loadDir, "./good")
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Equal(t, "github.com/haijima/analysisutil/testdata/src/load/good", pkgs[0].PkgPath)
+	assert.NotNil(t, pkgs[0].TypesInfo)
+	assert.NotEmpty(t, pkgs[0].Syntax)
+
+	_, err = analysisutil.LoadPackages(loadDir, "./good", "./broken")
+	assert.ErrorContains(t, err, "cannot use \"not an int\"")
+}
+
+func TestLoadPackagesWithConfig(t *testing.T) {
+	t.Run("Mode", func(t *testing.T) {
+		pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, Mode: packages.NeedName}, "./good")
+		require.NoError(t, err)
+		require.Len(t, pkgs, 1)
+		assert.Equal(t, "good", pkgs[0].Name)
+		assert.Nil(t, pkgs[0].Types)
+	})
+
+	t.Run("Tests", func(t *testing.T) {
+		pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, Tests: true}, "./good")
+		require.NoError(t, err)
+		ids := make([]string, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			ids = append(ids, pkg.ID)
+		}
+		assert.Contains(t, ids, "github.com/haijima/analysisutil/testdata/src/load/good.test")
+
+		pkgs, err = analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir}, "./good")
+		require.NoError(t, err)
+		assert.Len(t, pkgs, 1)
+	})
+
+	t.Run("BuildTags", func(t *testing.T) {
+		pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir}, "./tagged")
+		require.NoError(t, err)
+		assert.Equal(t, constant.MakeBool(false), constOf(t, pkgs[0], "Extra"))
+
+		pkgs, err = analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, BuildTags: []string{"extra"}}, "./tagged")
+		require.NoError(t, err)
+		assert.Equal(t, constant.MakeBool(true), constOf(t, pkgs[0], "Extra"))
+
+		pkgs, err = analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, BuildFlags: []string{"-tags=extra"}}, "./tagged")
+		require.NoError(t, err)
+		assert.Equal(t, constant.MakeBool(true), constOf(t, pkgs[0], "Extra"))
+	})
+
+	t.Run("GOOS", func(t *testing.T) {
+		for _, goos := range []string{"linux", "windows", "darwin"} {
+			pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, GOOS: goos, GOARCH: "amd64"}, "./platform")
+			require.NoError(t, err, goos)
+			assert.Equal(t, constant.MakeString(goos), constOf(t, pkgs[0], "OS"), goos)
+			assert.Equal(t, []string{"platform_" + goos + ".go"}, baseNames(pkgs[0].GoFiles), goos)
+		}
+	})
+
+	t.Run("Overlay", func(t *testing.T) {
+		dir, err := filepath.Abs(loadDir)
+		require.NoError(t, err)
+		overlay := map[string][]byte{
+			filepath.Join(dir, "good", "good.go"): []byte("package good\n\nconst Overlaid = 1\n"),
+		}
+		pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: loadDir, Overlay: overlay}, "./good")
+		require.NoError(t, err)
+		assert.Equal(t, constant.MakeInt64(1), constOf(t, pkgs[0], "Overlaid"))
+		assert.Nil(t, pkgs[0].Types.Scope().Lookup("Hello"))
+	})
+}
+
+func TestLoadPackagesPartial(t *testing.T) {
+	pkgs, pkgErrs, err := analysisutil.LoadPackagesPartial(&analysisutil.LoadConfig{Dir: loadDir}, "./good", "./broken", "./usesbroken")
+	require.NoError(t, err)
+
+	paths := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		paths = append(paths, pkg.PkgPath)
+	}
+	// usesbroken has no errors of its own but depends on a broken package
+	assert.Equal(t, []string{"github.com/haijima/analysisutil/testdata/src/load/good"}, paths)
+
+	require.Len(t, pkgErrs, 1)
+	pkgErr := pkgErrs[0]
+	assert.Equal(t, "github.com/haijima/analysisutil/testdata/src/load/broken", pkgErr.PkgPath)
+	assert.Equal(t, packages.TypeError, pkgErr.Kind)
+	assert.Equal(t, "type", pkgErr.KindString())
+	assert.True(t, strings.HasSuffix(pkgErr.Pos, filepath.Join("broken", "broken.go")+":4:9"), pkgErr.Pos)
+	assert.True(t, strings.HasPrefix(pkgErr.Error(), pkgErr.PkgPath+": "), pkgErr.Error())
+	assert.Contains(t, pkgErr.Error(), pkgErr.Msg)
+
+	pkgs, pkgErrs, err = analysisutil.LoadPackagesPartial(&analysisutil.LoadConfig{Dir: loadDir}, "./good")
+	require.NoError(t, err)
+	assert.Len(t, pkgs, 1)
+	assert.Empty(t, pkgErrs)
+}
+
+func baseNames(files []string) []string {
+	res := make([]string, 0, len(files))
+	for _, f := range files {
+		res = append(res, filepath.Base(f))
+	}
+	slices.Sort(res)
+	return res
+}
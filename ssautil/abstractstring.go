@@ -0,0 +1,202 @@
+package ssautil
+
+import (
+	"go/token"
+	"go/types"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// StringFragment is a fragment of an AbstractString, either a literal or a hole that stands for any string.
+type StringFragment struct {
+	Literal string
+	Hole    bool
+}
+
+// AbstractString is a string value whose unknown parts are holes, e.g. "SELECT * FROM " + table + " WHERE id = ?"
+// is the literal "SELECT * FROM ", a hole and the literal " WHERE id = ?".
+// Adjacent literals and adjacent holes are merged, so equal strings have equal fragments.
+type AbstractString []StringFragment
+
+// LiteralString returns the AbstractString of the constant s.
+func LiteralString(s string) AbstractString {
+	if s == "" {
+		return AbstractString{}
+	}
+	return AbstractString{{Literal: s}}
+}
+
+// UnknownString returns the AbstractString of a string that is not known at all.
+func UnknownString() AbstractString {
+	return AbstractString{{Hole: true}}
+}
+
+// Concat returns the concatenation of a and b.
+func (a AbstractString) Concat(b AbstractString) AbstractString {
+	res := slices.Clone(a)
+	for _, f := range b {
+		if n := len(res); n > 0 && res[n-1].Hole == f.Hole {
+			res[n-1].Literal += f.Literal
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// IsConst reports whether a has no holes.
+func (a AbstractString) IsConst() bool {
+	return !slices.ContainsFunc(a, func(f StringFragment) bool { return f.Hole })
+}
+
+// Prefix returns the literal before the first hole.
+func (a AbstractString) Prefix() string {
+	if len(a) == 0 || a[0].Hole {
+		return ""
+	}
+	return a[0].Literal
+}
+
+// Suffix returns the literal after the last hole.
+func (a AbstractString) Suffix() string {
+	if len(a) == 0 || a[len(a)-1].Hole {
+		return ""
+	}
+	return a[len(a)-1].Literal
+}
+
+// String returns the literals with the holes rendered as "<?>".
+func (a AbstractString) String() string {
+	var sb strings.Builder
+	for _, f := range a {
+		if f.Hole {
+			sb.WriteString("<?>")
+		} else {
+			sb.WriteString(f.Literal)
+		}
+	}
+	return sb.String()
+}
+
+// Regexp returns a regular expression that matches the strings that a may be.
+func (a AbstractString) Regexp() *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, f := range a {
+		if f.Hole {
+			sb.WriteString("(?s:.*)")
+		} else {
+			sb.WriteString(regexp.QuoteMeta(f.Literal))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// Glob returns a glob pattern as accepted by path.Match where each hole is "*" and the literals are escaped.
+// Note that "*" in path.Match does not match "/".
+func (a AbstractString) Glob() string {
+	var sb strings.Builder
+	for _, f := range a {
+		if f.Hole {
+			sb.WriteString("*")
+			continue
+		}
+		for _, r := range f.Literal {
+			if strings.ContainsRune(`*?[\`, r) {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// Match reports whether s is one of the strings that a may be.
+func (a AbstractString) Match(s string) bool {
+	return a.Regexp().MatchString(s)
+}
+
+// ValueToAbstractStrings returns the possible values of the string value v as abstract strings.
+// Unlike ValueToStrings, the parts of a concatenation that cannot be resolved become holes instead of failing,
+// and so do the merged values that cannot be resolved, such as an edge of a Phi or an argument of a call site.
+// It is false if nothing is known about v.
+func ValueToAbstractStrings(v ssa.Value) ([]AbstractString, bool) {
+	return ValueToAbstractStringsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToAbstractStringsWithOptions(v ssa.Value, opts ResolveOptions) ([]AbstractString, bool) {
+	r := &resolver[AbstractString]{
+		opts: opts,
+		flattener: func(v ssa.Value, next func(v ssa.Value) ([]AbstractString, bool)) ([]AbstractString, bool) {
+			switch t := v.(type) {
+			case *ssa.BinOp:
+				if t.Op == token.ADD && isString(t.Type()) {
+					return concatAll(orUnknown(next(t.X)), orUnknown(next(t.Y))), true
+				}
+			case *ssa.Call:
+				ctx := &ModelContext{call: t, opts: opts, strs: next, hole: unknownHole,
+					ints: func(v ssa.Value) ([]int, bool) { return ValueToIntsWithOptions(v, crossOptions(opts)) }}
+				return modelsOf(opts).stringsOf(GetCallInfo(t.Common()), ctx)
+			case *ssa.Slice:
+				// slicing is only modeled on constant strings
+				if strs, ok := ValueToStringsWithOptions(t, opts); ok {
+					res := make([]AbstractString, 0, len(strs))
+					for _, s := range strs {
+						res = append(res, LiteralString(s))
+					}
+					return res, true
+				}
+			}
+			return []AbstractString{}, false
+		},
+		mapper: func(t *ssa.Const) (AbstractString, bool) {
+			if s, ok := constToString(t); ok {
+				return LiteralString(s), true
+			}
+			return nil, false
+		},
+		unknown: UnknownString,
+	}
+	res, ok := r.resolve(v, resolveState{})
+	if !ok {
+		return []AbstractString{UnknownString()}, false
+	}
+
+	uniq := make([]AbstractString, 0, len(res))
+	for _, a := range res {
+		if !slices.ContainsFunc(uniq, func(u AbstractString) bool { return slices.Equal(u, a) }) {
+			uniq = append(uniq, a)
+		}
+	}
+	return uniq, true
+}
+
+func unknownHole(rune) (AbstractString, bool) {
+	return UnknownString(), true
+}
+
+func orUnknown(as []AbstractString, ok bool) []AbstractString {
+	if !ok || len(as) == 0 {
+		return []AbstractString{UnknownString()}
+	}
+	return as
+}
+
+func concatAll(xs, ys []AbstractString) []AbstractString {
+	res := make([]AbstractString, 0, len(xs)*len(ys))
+	for _, x := range xs {
+		for _, y := range ys {
+			res = append(res, x.Concat(y))
+		}
+	}
+	return res
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
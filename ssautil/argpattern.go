@@ -0,0 +1,200 @@
+package ssautil
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ArgConstraint is a constraint on an argument of a call in a Pattern. It is one of
+//
+//	_, *                      any argument
+//	"str", `str`              a string equal to str
+//	prefix("str")             a string starting with str
+//	suffix("str")             a string ending with str
+//	contains("str")           a string containing str
+//	regexp("re")              a string matching the regular expression re
+//	42, -1                    an integer equal to the number
+//	<n, <=n, >n, >=n, ==n, !=n an integer compared with n
+//
+// The last constraint of an argument list may be "..." to match any remaining arguments.
+// A constraint may be keyed by a parameter name as name=constraint to apply to the argument looked up by
+// CallInfo.ArgByName. Keyed constraints follow the positional ones and do not constrain the number of arguments.
+// String and integer arguments are resolved by ValueToStrings and ValueToInts respectively,
+// and a constraint is satisfied only if the argument is resolved and all its possible values satisfy it.
+type ArgConstraint struct {
+	src  string
+	name string // parameter name, or empty for a positional constraint
+	str  func(s string) bool
+	int  func(i int) bool
+}
+
+// ParseArgConstraint compiles a single argument constraint. See ArgConstraint for the syntax.
+func ParseArgConstraint(s string) (*ArgConstraint, error) {
+	s = strings.TrimSpace(s)
+	a := &ArgConstraint{src: s}
+	switch {
+	case s == "_" || s == "*":
+	case strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`"):
+		lit, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid string literal %s", s)
+		}
+		a.str = func(v string) bool { return v == lit }
+	case strings.HasSuffix(s, ")") && strings.Contains(s, "("):
+		fn, arg, _ := strings.Cut(s[:len(s)-1], "(")
+		lit, err := strconv.Unquote(strings.TrimSpace(arg))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid string literal in %s", s)
+		}
+		switch strings.TrimSpace(fn) {
+		case "prefix":
+			a.str = func(v string) bool { return strings.HasPrefix(v, lit) }
+		case "suffix":
+			a.str = func(v string) bool { return strings.HasSuffix(v, lit) }
+		case "contains":
+			a.str = func(v string) bool { return strings.Contains(v, lit) }
+		case "regexp":
+			re, err := regexp.Compile(lit)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regexp in %s", s)
+			}
+			a.str = re.MatchString
+		default:
+			return nil, errors.Newf("unknown argument constraint %s", s)
+		}
+	default:
+		op := ""
+		for _, o := range []string{"<=", ">=", "==", "!=", "<", ">"} {
+			if strings.HasPrefix(s, o) {
+				op = o
+				break
+			}
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(s[len(op):]))
+		if err != nil {
+			return nil, errors.Newf("unknown argument constraint %s", s)
+		}
+		switch op {
+		case "", "==":
+			a.int = func(v int) bool { return v == n }
+		case "!=":
+			a.int = func(v int) bool { return v != n }
+		case "<":
+			a.int = func(v int) bool { return v < n }
+		case "<=":
+			a.int = func(v int) bool { return v <= n }
+		case ">":
+			a.int = func(v int) bool { return v > n }
+		case ">=":
+			a.int = func(v int) bool { return v >= n }
+		}
+	}
+	return a, nil
+}
+
+func (a *ArgConstraint) String() string {
+	if a.name != "" {
+		return a.name + "=" + a.src
+	}
+	return a.src
+}
+
+// Name returns the parameter name the constraint is keyed by, or "" if it is positional.
+func (a *ArgConstraint) Name() string {
+	return a.name
+}
+
+// Match reports whether the argument v satisfies the constraint.
+func (a *ArgConstraint) Match(v ssa.Value) bool {
+	if mi, ok := v.(*ssa.MakeInterface); ok {
+		v = mi.X // e.g. an argument of a ...any parameter
+	}
+	switch {
+	case a.str != nil:
+		vs, ok := ValueToStrings(v)
+		return ok && allSatisfy(vs, a.str)
+	case a.int != nil:
+		vs, ok := ValueToInts(v)
+		return ok && allSatisfy(vs, a.int)
+	default:
+		return true
+	}
+}
+
+func allSatisfy[T any](vs []T, fn func(T) bool) bool {
+	for _, v := range vs {
+		if !fn(v) {
+			return false
+		}
+	}
+	return len(vs) > 0
+}
+
+var keyedArgRe = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=([^=].*)$`)
+
+// parseArgConstraints parses a comma-separated argument list of a pattern.
+func parseArgConstraints(s string) ([]*ArgConstraint, bool, error) {
+	res := make([]*ArgConstraint, 0)
+	if strings.TrimSpace(s) == "" {
+		return res, false, nil
+	}
+	args, err := splitTopLevel(s, ',')
+	if err != nil {
+		return nil, false, err
+	}
+	for i, arg := range args {
+		if strings.TrimSpace(arg) == "..." {
+			if i != len(args)-1 {
+				return nil, false, errors.Newf("... must be the last argument in %q", s)
+			}
+			return res, true, nil
+		}
+		name := ""
+		if m := keyedArgRe.FindStringSubmatch(arg); m != nil {
+			name, arg = m[1], m[2]
+		} else if len(res) > 0 && res[len(res)-1].name != "" {
+			return nil, false, errors.Newf("positional argument %s follows a keyed argument in %q", strings.TrimSpace(arg), s)
+		}
+		a, err := ParseArgConstraint(arg)
+		if err != nil {
+			return nil, false, err
+		}
+		a.name = name
+		res = append(res, a)
+	}
+	return res, false, nil
+}
+
+// matchArgs reports whether the arguments of c satisfy the argument constraints of alt.
+func (alt *patternAlt) matchArgs(c CallInfo) bool {
+	if alt.args == nil {
+		return true
+	}
+	if c == nil {
+		return false
+	}
+	args := c.Args()
+	positional := slices.IndexFunc(alt.args, func(a *ArgConstraint) bool { return a.name != "" })
+	if positional < 0 {
+		positional = len(alt.args)
+	}
+	rest := alt.restArgs || positional < len(alt.args)
+	if n := len(args); n < positional || (!rest && n != positional) {
+		return false
+	}
+	for i, a := range alt.args {
+		v, ok := argOK(args, i)
+		if a.name != "" {
+			v, ok = c.ArgByName(a.name)
+		}
+		if !ok || !a.Match(v) {
+			return false
+		}
+	}
+	return true
+}
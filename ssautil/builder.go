@@ -0,0 +1,223 @@
+package ssautil
+
+import (
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	// maxBuilderVisits is the maximum number of times a block is visited on a path, which unrolls loops
+	// to run at most maxBuilderVisits-1 times.
+	maxBuilderVisits = 3
+	// maxBuilderSteps is the maximum number of blocks visited on all paths.
+	maxBuilderSteps = 1000
+)
+
+// builderModel models the String method of a local strings.Builder or bytes.Buffer.
+// The writes to it are tracked in program order on every path from the entry of the function to the call,
+// where loops are unrolled a bounded number of times.
+type builderModel struct {
+	ctx   *ModelContext
+	buf   *ssa.Alloc
+	call  *ssa.Call
+	steps int
+	res   []AbstractString
+	// path is the blocks on the path being followed
+	path []*ssa.BasicBlock
+}
+
+// builderStringModel is the StringModel of (*strings.Builder).String and (*bytes.Buffer).String.
+func builderStringModel(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	smc, ok := c.(*StaticMethodCall)
+	if !ok {
+		return nil, false
+	}
+	buf, ok := smc.Recv().(*ssa.Alloc)
+	if !ok || buf.Parent() != ctx.Call().Parent() || !isTrackedBuilder(buf) {
+		return nil, false
+	}
+	m := &builderModel{ctx: ctx, buf: buf, call: ctx.Call()}
+	visits := make(map[*ssa.BasicBlock]int)
+	if !m.walk(buf.Parent().Blocks[0], []AbstractString{{}}, visits) || len(m.res) == 0 {
+		return nil, false
+	}
+	return m.res, true
+}
+
+// isTrackedBuilder reports whether every use of buf is a call or a conversion to an interface passed to a call,
+// so that no write can go through another pointer.
+func isTrackedBuilder(buf *ssa.Alloc) bool {
+	for _, ref := range *buf.Referrers() {
+		switch ref := ref.(type) {
+		case ssa.CallInstruction, *ssa.DebugRef:
+		case *ssa.MakeInterface:
+			for _, iref := range *ref.Referrers() {
+				if _, ok := iref.(ssa.CallInstruction); !ok {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// walk follows the paths from b with the possible contents acc of the buffer, and adds the contents at the call to m.res.
+// A path that runs a loop writing to the buffer too many times may write anything more before the call,
+// which is a hole. A path that runs a loop not writing to it has the contents of a path running it fewer times.
+func (m *builderModel) walk(b *ssa.BasicBlock, acc []AbstractString, visits map[*ssa.BasicBlock]int) bool {
+	if visits[b] >= maxBuilderVisits {
+		loop := m.path[slices.Index(m.path, b):]
+		if !slices.ContainsFunc(loop, m.writesIn) {
+			return true
+		}
+		acc, ok := m.appendHole(acc, 'v')
+		return ok && m.add(acc)
+	}
+	if m.steps++; m.steps > maxBuilderSteps {
+		return false
+	}
+	visits[b]++
+	m.path = append(m.path, b)
+	defer func() {
+		visits[b]--
+		m.path = m.path[:len(m.path)-1]
+	}()
+
+	for _, instr := range b.Instrs {
+		if instr == m.call {
+			return m.add(acc)
+		}
+		site, ok := instr.(ssa.CallInstruction)
+		if !ok {
+			continue
+		}
+		if acc, ok = m.write(site, acc); !ok {
+			return false
+		}
+	}
+	for _, succ := range b.Succs {
+		if !m.walk(succ, acc, visits) {
+			return false
+		}
+	}
+	return true
+}
+
+// writesIn reports whether a call in b may change the contents of the buffer.
+func (m *builderModel) writesIn(b *ssa.BasicBlock) bool {
+	for _, instr := range b.Instrs {
+		site, ok := instr.(ssa.CallInstruction)
+		if !ok {
+			continue
+		}
+		c := GetCallInfo(site.Common())
+		if smc, ok := c.(*StaticMethodCall); ok && smc.Recv() == m.buf {
+			switch smc.Method().Name() {
+			case "String", "Len", "Cap", "Grow", "Available", "AvailableBuffer":
+				continue
+			}
+			return true
+		}
+		if slices.ContainsFunc(c.Args(), m.refersToBuf) {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds the contents acc at the call to m.res.
+func (m *builderModel) add(acc []AbstractString) bool {
+	for _, a := range acc {
+		if !slices.ContainsFunc(m.res, func(r AbstractString) bool { return slices.Equal(r, a) }) {
+			m.res = append(m.res, a)
+		}
+	}
+	return len(m.res) <= maxFmtResults
+}
+
+// write returns the possible contents of the buffer after site, given the contents acc before it.
+func (m *builderModel) write(site ssa.CallInstruction, acc []AbstractString) ([]AbstractString, bool) {
+	c := GetCallInfo(site.Common())
+	if smc, ok := c.(*StaticMethodCall); ok && smc.Recv() == m.buf {
+		switch smc.Method().Name() {
+		case "WriteString":
+			return m.appendString(acc, smc.Arg(0))
+		case "Write":
+			return m.appendBytes(acc, smc.Arg(0))
+		case "WriteByte", "WriteRune":
+			ints, ok := m.ctx.Ints(smc.Arg(0))
+			if !ok {
+				return m.appendHole(acc, 'v') // not a sample of %c, which is only for the fmt model
+			}
+			strs := make([]AbstractString, 0, len(ints))
+			for _, i := range ints {
+				if smc.Method().Name() == "WriteByte" {
+					strs = append(strs, LiteralString(string([]byte{byte(i)})))
+				} else {
+					strs = append(strs, LiteralString(string(rune(i))))
+				}
+			}
+			return productOf(acc, strs)
+		case "Reset":
+			return []AbstractString{{}}, true
+		case "String", "Len", "Cap", "Grow", "Available", "AvailableBuffer":
+			return acc, true
+		}
+		return m.appendHole(acc, 'v') // e.g. Truncate and ReadFrom
+	}
+
+	args := c.Args()
+	if !slices.ContainsFunc(args, m.refersToBuf) {
+		return acc, true
+	}
+	if c.Match("fmt.Fprintf|fmt.Fprint|fmt.Fprintln") && m.refersToBuf(args[0]) && !isSpread(args[len(args)-1]) {
+		// e.g. fmt.Fprintf(&b, format, args...)
+		fm := &fmtModel{ctx: m.ctx}
+		var strs []AbstractString
+		var ok bool
+		if c.Match("fmt.Fprintf") {
+			strs, ok = fm.formatted(nil, args[1], args[2:])
+		} else {
+			strs, ok = fm.sprint(args[1:], c.Match("fmt.Fprintln"))
+		}
+		if !ok {
+			return m.appendHole(acc, 'v')
+		}
+		return productOf(acc, strs)
+	}
+	return m.appendHole(acc, 'v') // the buffer is passed to an unknown function
+}
+
+func (m *builderModel) refersToBuf(v ssa.Value) bool {
+	if mi, ok := v.(*ssa.MakeInterface); ok {
+		v = mi.X
+	}
+	return v == m.buf
+}
+
+func (m *builderModel) appendString(acc []AbstractString, v ssa.Value) ([]AbstractString, bool) {
+	strs, ok := m.ctx.Strings(v)
+	if !ok {
+		return m.appendHole(acc, 's')
+	}
+	return productOf(acc, strs)
+}
+
+func (m *builderModel) appendBytes(acc []AbstractString, v ssa.Value) ([]AbstractString, bool) {
+	// e.g. b.Write([]byte("..."))
+	if conv, ok := v.(*ssa.Convert); ok && isString(conv.X.Type()) {
+		return m.appendString(acc, conv.X)
+	}
+	return m.appendHole(acc, 's')
+}
+
+func (m *builderModel) appendHole(acc []AbstractString, verb rune) ([]AbstractString, bool) {
+	h, ok := m.ctx.Hole(verb)
+	if !ok {
+		return nil, false
+	}
+	return productOf(acc, []AbstractString{h})
+}
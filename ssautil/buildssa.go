@@ -11,35 +11,29 @@ import (
 )
 
 func LoadBuildSSAs(dir string, patterns ...string) ([]*buildssa.SSA, error) {
-	pkgs, err := analysisutil.LoadPackages(dir, patterns...)
+	prog, err := LoadProgram(dir, patterns...)
 	if err != nil {
 		return nil, err
 	}
+	return prog.Packages, nil
+}
 
-	ssaProgs := make([]*buildssa.SSA, 0, len(pkgs))
-	for _, pkg := range pkgs {
-		ssaProg, err := BuildSSA(pkg)
-		if err != nil {
-			return nil, err
-		}
-		ssaProgs = append(ssaProgs, ssaProg)
+func LoadProgram(dir string, patterns ...string) (*Program, error) {
+	pkgs, err := analysisutil.LoadPackages(dir, patterns...)
+	if err != nil {
+		return nil, err
 	}
-
-	return ssaProgs, nil
+	return BuildProgram(pkgs, ssa.BuilderMode(0))
 }
 
 func LoadInstrs(dir string, patterns ...string) ([]ssa.Instruction, error) {
-	pkgs, err := analysisutil.LoadPackages(dir, patterns...)
+	ssaProgs, err := LoadBuildSSAs(dir, patterns...)
 	if err != nil {
 		return nil, err
 	}
 
 	instrs := make([]ssa.Instruction, 0)
-	for _, pkg := range pkgs {
-		ssaProg, err := BuildSSA(pkg)
-		if err != nil {
-			return nil, err
-		}
+	for _, ssaProg := range ssaProgs {
 		for _, fn := range ssaProg.SrcFuncs {
 			for _, b := range fn.Blocks {
 				for _, instr := range b.Instrs {
@@ -64,8 +58,12 @@ func BuildSSA(pkg *packages.Package) (*buildssa.SSA, error) {
 	ssapkg := prog.CreatePackage(pkg.Types, pkg.Syntax, pkg.TypesInfo, false)
 	ssapkg.Build()
 
-	// Compute list of source functions, including literals,
-	// in source order.
+	return &buildssa.SSA{Pkg: ssapkg, SrcFuncs: srcFuncs(pkg, ssapkg)}, nil
+}
+
+// srcFuncs computes list of source functions, including literals,
+// in source order.
+func srcFuncs(pkg *packages.Package, ssapkg *ssa.Package) []*ssa.Function {
 	var funcs []*ssa.Function
 	for _, f := range pkg.Syntax {
 		for _, decl := range f.Decls {
@@ -88,6 +86,5 @@ func BuildSSA(pkg *packages.Package) (*buildssa.SSA, error) {
 			}
 		}
 	}
-
-	return &buildssa.SSA{Pkg: ssapkg, SrcFuncs: funcs}, nil
+	return funcs
 }
@@ -3,7 +3,7 @@ package ssautil
 import (
 	"fmt"
 	"go/types"
-	"regexp"
+	"slices"
 	"strings"
 
 	"golang.org/x/tools/go/ssa"
@@ -37,116 +37,193 @@ type PackageFunction interface {
 
 type StaticMethodCall struct {
 	ssa.CallCommon
+	m methodCall
 }
 
 func NewStaticMethodCall(common *ssa.CallCommon) *StaticMethodCall {
-	return &StaticMethodCall{CallCommon: *common}
+	return &StaticMethodCall{CallCommon: *common, m: newMethodCall(common)}
 }
 
 func (s *StaticMethodCall) String() string {
 	return s.Signature().String()
 }
 func (s *StaticMethodCall) Name() string {
-	return fmt.Sprintf("(%s).%s", s.Signature().Recv().Type(), s.Method().Name())
+	return fmt.Sprintf("(%s).%s", s.m.fn.Signature.Recv().Type(), s.Method().Name())
 }
 func (s *StaticMethodCall) Pkg() *types.Package {
-	return s.Signature().Recv().Pkg()
+	return s.m.fn.Signature.Recv().Pkg()
 }
 func (s *StaticMethodCall) Recv() ssa.Value {
-	return s.Args[0]
+	return s.m.recv
 }
 func (s *StaticMethodCall) Method() *types.Func {
-	return s.Value.(*ssa.Function).Object().(*types.Func)
+	return s.m.fn.Object().(*types.Func)
+}
+
+// Func returns the called method. Method values and method expressions are resolved to the method they wrap.
+func (s *StaticMethodCall) Func() *ssa.Function {
+	return s.m.fn
+}
+func (s *StaticMethodCall) Form() CallForm {
+	return s.m.form
 }
 func (s *StaticMethodCall) Arg(idx int) ssa.Value {
-	return s.Args[idx+1]
+	return s.CallCommon.Args[idx+s.m.skip]
 }
 func (s *StaticMethodCall) ArgsLen() int {
-	return len(s.Args) - 1
+	return len(s.CallCommon.Args) - s.m.skip
 }
-func (s *StaticMethodCall) Match(namePattern string) bool {
-	if s.Name() == namePattern || namePattern == "(*.*).*" {
-		return true
-	}
-	m := staticMethodNamePattern.FindStringSubmatch(namePattern)
-	if len(m) != 4 {
-		return false
+func (s *StaticMethodCall) Args() []ssa.Value {
+	return logicalArgs(&s.CallCommon, s.m.skip)
+}
+func (s *StaticMethodCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(s.Args(), idx)
+}
+func (s *StaticMethodCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&s.CallCommon, s.m.skip, originSignature(s.m.fn), name)
+}
+func (s *StaticMethodCall) TypeArgs() []types.Type {
+	return s.m.fn.TypeArgs()
+}
+func (s *StaticMethodCall) NameAs(form NameForm) string {
+	if origin := s.m.fn.Origin(); form == NameOrigin && origin != nil {
+		return fmt.Sprintf("(%s).%s", origin.Signature.Recv().Type(), s.Method().Name())
 	}
-	p := s.Pkg().Path()
-	r := strings.TrimPrefix(strings.TrimPrefix(s.Recv().Type().String(), "*"), p+".")
-	return (m[1] == p || m[1] == "*") && (m[2] == r || m[2] == "*") && (m[3] == s.Method().Name() || m[3] == "*")
+	return s.Name()
+}
+func (s *StaticMethodCall) Match(namePattern string) bool {
+	return matchPattern(s, namePattern)
 }
-
-var staticMethodNamePattern = regexp.MustCompile(`^\(\*?(.+)\.([^.]+)\)\.(.+)$`)
 
 type DynamicMethodCall struct {
 	ssa.CallCommon
+	m methodCall
 }
 
 func NewDynamicMethodCall(common *ssa.CallCommon) *DynamicMethodCall {
-	return &DynamicMethodCall{CallCommon: *common}
+	return &DynamicMethodCall{CallCommon: *common, m: newMethodCall(common)}
 }
 
 func (d *DynamicMethodCall) String() string {
 	return d.Signature().String()
 }
 func (d *DynamicMethodCall) Name() string {
-	return fmt.Sprintf("%s.%s", d.Recv().Type(), d.Method().Name())
+	return fmt.Sprintf("%s.%s", d.m.recvType(), d.Method().Name())
 }
 func (d *DynamicMethodCall) Pkg() *types.Package {
-	return d.Signature().Recv().Pkg()
+	return d.m.method.Type().(*types.Signature).Recv().Pkg()
 }
+
+// Recv returns the interface value the method is called on.
+// It is nil for a method value whose receiver is unknown, e.g. a possible target of a DynamicFunctionCall.
 func (d *DynamicMethodCall) Recv() ssa.Value {
-	return d.Value
+	return d.m.recv
 }
 func (d *DynamicMethodCall) Method() *types.Func {
-	return d.CallCommon.Method
+	return d.m.method
+}
+func (d *DynamicMethodCall) Form() CallForm {
+	return d.m.form
 }
 func (d *DynamicMethodCall) Arg(idx int) ssa.Value {
-	return d.Args[idx]
+	return d.CallCommon.Args[idx+d.m.skip]
 }
 func (d *DynamicMethodCall) ArgsLen() int {
-	return len(d.Args)
+	return len(d.CallCommon.Args) - d.m.skip
 }
-func (d *DynamicMethodCall) Match(namePattern string) bool {
-	if namePattern == d.Name() || namePattern == "*.*.*" {
-		return true
+func (d *DynamicMethodCall) Args() []ssa.Value {
+	return logicalArgs(&d.CallCommon, d.m.skip)
+}
+func (d *DynamicMethodCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(d.Args(), idx)
+}
+func (d *DynamicMethodCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&d.CallCommon, d.m.skip, funcSignature(d.Method()), name)
+}
+func (d *DynamicMethodCall) TypeArgs() []types.Type {
+	return namedTypeArgs(d.m.recvType())
+}
+func (d *DynamicMethodCall) NameAs(form NameForm) string {
+	if named, ok := d.m.recvType().(*types.Named); ok && form == NameOrigin && named.Origin() != named {
+		return fmt.Sprintf("%s.%s", originTypeString(named), d.Method().Name())
 	}
-	p := d.Pkg().Path()
-	r := strings.TrimPrefix(d.Recv().Type().String(), p+".")
-	m := d.Method().Name()
-	return namePattern == "*."+r+"."+m || namePattern == p+".*."+m || namePattern == p+"."+r+".*" ||
-		namePattern == "*.*."+m || namePattern == p+".*.*" || namePattern == "*."+r+".*"
+	return d.Name()
+}
+func (d *DynamicMethodCall) Match(namePattern string) bool {
+	return matchPattern(d, namePattern)
+}
+
+// Implementations returns the concrete methods that may receive the call.
+// Only the types that are converted to an interface somewhere in the program are considered.
+func (d *DynamicMethodCall) Implementations() []*ssa.Function {
+	return d.m.implementations()
+}
+
+// MatchImplementations reports whether the implementations of the call match a static method pattern
+// such as "(*pkg.Foo).String" in the given mode. It is false if no implementation is found.
+func (d *DynamicMethodCall) MatchImplementations(namePattern string, mode MatchMode) bool {
+	return matchFuncs(d.Implementations(), d, namePattern, mode)
 }
 
 type BuiltinDynamicMethodCall struct {
 	ssa.CallCommon
+	m methodCall
 }
 
 func NewBuiltinDynamicMethodCall(common *ssa.CallCommon) *BuiltinDynamicMethodCall {
-	return &BuiltinDynamicMethodCall{CallCommon: *common}
+	return &BuiltinDynamicMethodCall{CallCommon: *common, m: newMethodCall(common)}
 }
 
 func (b *BuiltinDynamicMethodCall) String() string {
 	return b.Signature().String()
 }
 func (b *BuiltinDynamicMethodCall) Name() string {
-	return fmt.Sprintf("%s.%s", b.Recv().Type(), b.Method().Name())
+	return fmt.Sprintf("%s.%s", b.m.recvType(), b.Method().Name())
 }
+
+// Recv returns the interface value the method is called on. See DynamicMethodCall.Recv.
 func (b *BuiltinDynamicMethodCall) Recv() ssa.Value {
-	return b.Value
+	return b.m.recv
 }
 func (b *BuiltinDynamicMethodCall) Method() *types.Func {
-	return b.CallCommon.Method
+	return b.m.method
+}
+func (b *BuiltinDynamicMethodCall) Form() CallForm {
+	return b.m.form
 }
 func (b *BuiltinDynamicMethodCall) Arg(idx int) ssa.Value {
-	return b.Args[idx]
+	return b.CallCommon.Args[idx+b.m.skip]
 }
 func (b *BuiltinDynamicMethodCall) ArgsLen() int {
-	return len(b.Args)
+	return len(b.CallCommon.Args) - b.m.skip
+}
+func (b *BuiltinDynamicMethodCall) Args() []ssa.Value {
+	return logicalArgs(&b.CallCommon, b.m.skip)
+}
+func (b *BuiltinDynamicMethodCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(b.Args(), idx)
+}
+func (b *BuiltinDynamicMethodCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&b.CallCommon, b.m.skip, funcSignature(b.Method()), name)
+}
+func (b *BuiltinDynamicMethodCall) TypeArgs() []types.Type {
+	return nil
+}
+func (b *BuiltinDynamicMethodCall) NameAs(form NameForm) string {
+	return b.Name()
 }
 func (b *BuiltinDynamicMethodCall) Match(namePattern string) bool {
-	return namePattern == b.Name() || namePattern == "*.*" || namePattern == "*."+b.Method().Name() || namePattern == b.Recv().Type().String()+".*"
+	return matchPattern(b, namePattern)
+}
+
+// Implementations returns the concrete methods that may receive the call. See DynamicMethodCall.Implementations.
+func (b *BuiltinDynamicMethodCall) Implementations() []*ssa.Function {
+	return b.m.implementations()
+}
+
+// MatchImplementations reports whether the implementations of the call match namePattern in the given mode.
+func (b *BuiltinDynamicMethodCall) MatchImplementations(namePattern string, mode MatchMode) bool {
+	return matchFuncs(b.Implementations(), b, namePattern, mode)
 }
 
 type StaticFunctionCall struct {
@@ -174,13 +251,34 @@ func (s *StaticFunctionCall) Func() *ssa.Function {
 	return fn.Origin() // generics static function call
 }
 func (s *StaticFunctionCall) Arg(idx int) ssa.Value {
-	return s.Args[idx]
+	return s.CallCommon.Args[idx]
 }
 func (s *StaticFunctionCall) ArgsLen() int {
-	return len(s.Args)
+	return len(s.CallCommon.Args)
+}
+func (s *StaticFunctionCall) Args() []ssa.Value {
+	return logicalArgs(&s.CallCommon, 0)
+}
+func (s *StaticFunctionCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(s.Args(), idx)
+}
+func (s *StaticFunctionCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&s.CallCommon, 0, originSignature(s.Func()), name)
+}
+func (s *StaticFunctionCall) TypeArgs() []types.Type {
+	return s.Value.(*ssa.Function).TypeArgs()
+}
+func (s *StaticFunctionCall) NameAs(form NameForm) string {
+	if targs := s.TypeArgs(); form == NameInstance && len(targs) > 0 {
+		return s.Name() + typeArgsString(targs)
+	}
+	return s.Name()
+}
+func (s *StaticFunctionCall) Form() CallForm {
+	return FormDirect
 }
 func (s *StaticFunctionCall) Match(namePattern string) bool {
-	return namePattern == s.Name() || namePattern == "*.*" || namePattern == "*."+s.Func().Name() || namePattern == s.Pkg().Path()+".*"
+	return matchPattern(s, namePattern)
 }
 
 type BuiltinStaticFunctionCall struct {
@@ -201,13 +299,31 @@ func (b *BuiltinStaticFunctionCall) Func() *ssa.Builtin {
 	return b.Value.(*ssa.Builtin)
 }
 func (b *BuiltinStaticFunctionCall) Arg(idx int) ssa.Value {
-	return b.Args[idx]
+	return b.CallCommon.Args[idx]
 }
 func (b *BuiltinStaticFunctionCall) ArgsLen() int {
-	return len(b.Args)
+	return len(b.CallCommon.Args)
+}
+func (b *BuiltinStaticFunctionCall) Args() []ssa.Value {
+	return logicalArgs(&b.CallCommon, 0)
+}
+func (b *BuiltinStaticFunctionCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(b.Args(), idx)
+}
+func (b *BuiltinStaticFunctionCall) ArgByName(name string) (ssa.Value, bool) {
+	return nil, false // parameters of builtins are not named
+}
+func (b *BuiltinStaticFunctionCall) TypeArgs() []types.Type {
+	return nil
+}
+func (b *BuiltinStaticFunctionCall) NameAs(form NameForm) string {
+	return b.Name()
+}
+func (b *BuiltinStaticFunctionCall) Form() CallForm {
+	return FormDirect
 }
 func (b *BuiltinStaticFunctionCall) Match(namePattern string) bool {
-	return namePattern == b.Name() || namePattern == "*"
+	return matchPattern(b, namePattern)
 }
 
 type StaticFunctionClosureCall struct {
@@ -234,13 +350,31 @@ func (s *StaticFunctionClosureCall) Func() *ssa.Function {
 	return s.Value.(*ssa.MakeClosure).Fn.(*ssa.Function)
 }
 func (s *StaticFunctionClosureCall) Arg(idx int) ssa.Value {
-	return s.Args[idx]
+	return s.CallCommon.Args[idx]
 }
 func (s *StaticFunctionClosureCall) ArgsLen() int {
-	return len(s.Args)
+	return len(s.CallCommon.Args)
+}
+func (s *StaticFunctionClosureCall) Args() []ssa.Value {
+	return logicalArgs(&s.CallCommon, 0)
+}
+func (s *StaticFunctionClosureCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(s.Args(), idx)
+}
+func (s *StaticFunctionClosureCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&s.CallCommon, 0, originSignature(s.Func()), name)
+}
+func (s *StaticFunctionClosureCall) TypeArgs() []types.Type {
+	return s.Func().TypeArgs()
+}
+func (s *StaticFunctionClosureCall) NameAs(form NameForm) string {
+	return s.Name()
+}
+func (s *StaticFunctionClosureCall) Form() CallForm {
+	return FormDirect
 }
 func (s *StaticFunctionClosureCall) Match(namePattern string) bool {
-	return namePattern == s.Name() || namePattern == "*.*" || namePattern == "*."+s.Func().Name() || namePattern == s.Pkg().Path()+".*"
+	return matchPattern(s, namePattern)
 }
 
 type DynamicFunctionCall struct {
@@ -265,23 +399,215 @@ func (d *DynamicFunctionCall) Name() string {
 	}
 }
 func (d *DynamicFunctionCall) Arg(idx int) ssa.Value {
-	return d.Args[idx]
+	return d.CallCommon.Args[idx]
 }
 func (d *DynamicFunctionCall) ArgsLen() int {
-	return len(d.Args)
+	return len(d.CallCommon.Args)
+}
+func (d *DynamicFunctionCall) Args() []ssa.Value {
+	return logicalArgs(&d.CallCommon, 0)
+}
+func (d *DynamicFunctionCall) ArgOK(idx int) (ssa.Value, bool) {
+	return argOK(d.Args(), idx)
+}
+func (d *DynamicFunctionCall) ArgByName(name string) (ssa.Value, bool) {
+	return argByName(&d.CallCommon, 0, d.Signature(), name)
+}
+func (d *DynamicFunctionCall) TypeArgs() []types.Type {
+	return nil
 }
+func (d *DynamicFunctionCall) NameAs(form NameForm) string {
+	return d.Name()
+}
+func (d *DynamicFunctionCall) Form() CallForm {
+	return FormDirect
+}
+
+// Targets returns the possible functions that are called, resolved from the callee value by ValueToFuncs.
+// Unlike the default options, up to 3 calls are followed, so that functions passed as arguments are resolved.
+func (d *DynamicFunctionCall) Targets() ([]*ssa.Function, bool) {
+	opts := DefaultResolveOptions()
+	opts.MaxCallDepth = 3
+	return ValueToFuncsWithOptions(d.Value, opts)
+}
+
+// Match reports whether every target of the call matches namePattern. See MatchTargets.
 func (d *DynamicFunctionCall) Match(namePattern string) bool {
-	return false
+	return matchPattern(d, namePattern)
+}
+
+// MatchTargets reports whether the targets of the call match namePattern in the given mode.
+// It is false if no target is resolved.
+func (d *DynamicFunctionCall) MatchTargets(namePattern string, mode MatchMode) bool {
+	targets, _ := d.Targets()
+	return matchFuncs(targets, d, namePattern, mode)
+}
+
+// MatchMode is how the possible targets of a dynamic call are matched against a pattern.
+type MatchMode int
+
+const (
+	// MatchAll matches if every target matches.
+	MatchAll MatchMode = iota
+	// MatchAny matches if any target matches.
+	MatchAny
+)
+
+// matchFuncs matches fns as the possible callees of c against namePattern in the given mode.
+func matchFuncs(fns []*ssa.Function, c CallInfo, namePattern string, mode MatchMode) bool {
+	p := cachedPattern(namePattern)
+	return p != nil && p.matchFuncs(fns, c, mode)
+}
+
+// CallForm is the syntactic form in which a method is called.
+type CallForm int
+
+const (
+	// FormDirect is an ordinary call such as x.M() or f().
+	FormDirect CallForm = iota
+	// FormMethodValue is a call of a method value such as f := x.M; f(), made through a bound method wrapper ($bound).
+	FormMethodValue
+	// FormMethodExpr is a call of a method expression such as T.M(x), made through a thunk ($thunk).
+	FormMethodExpr
+)
+
+func (f CallForm) String() string {
+	switch f {
+	case FormDirect:
+		return "direct"
+	case FormMethodValue:
+		return "method value"
+	case FormMethodExpr:
+		return "method expression"
+	default:
+		return "unknown"
+	}
+}
+
+// methodCall describes the method called by a call of any form.
+type methodCall struct {
+	form   CallForm
+	recv   ssa.Value // nil if unknown
+	method *types.Func
+	fn     *ssa.Function // the concrete method, nil for interface methods
+	skip   int           // the number of leading arguments that are the receiver
+}
+
+func newMethodCall(common *ssa.CallCommon) methodCall {
+	if common.IsInvoke() {
+		return methodCall{form: FormDirect, recv: common.Value, method: common.Method}
+	}
+	var m methodCall
+	var fn *ssa.Function
+	switch v := common.Value.(type) {
+	case *ssa.Function:
+		fn = v
+	case *ssa.MakeClosure:
+		fn = v.Fn.(*ssa.Function)
+		if len(v.Bindings) > 0 {
+			m.recv = v.Bindings[0]
+		}
+	}
+	m.method = fn.Object().(*types.Func)
+	switch wrapperForm(fn) {
+	case FormMethodValue:
+		m.form = FormMethodValue
+	case FormMethodExpr:
+		m.form, m.skip = FormMethodExpr, 1
+	default:
+		m.skip, m.fn = 1, fn
+	}
+	if m.skip == 1 && len(common.Args) > 0 {
+		m.recv = common.Args[0]
+	}
+	if m.fn == nil && !types.IsInterface(m.method.Type().(*types.Signature).Recv().Type()) {
+		m.fn = wrappedMethod(fn)
+	}
+	return m
+}
+
+// recvType returns the type of the receiver, or the receiver type of the method if the receiver is unknown.
+func (m *methodCall) recvType() types.Type {
+	if m.recv != nil {
+		return m.recv.Type()
+	}
+	return m.method.Type().(*types.Signature).Recv().Type()
+}
+
+// implementations returns the concrete methods that the call of an interface method may dispatch to,
+// considering the types converted to an interface in the program, which are computed once for the program.
+func (m *methodCall) implementations() []*ssa.Function {
+	if m.recv == nil || m.recv.Parent() == nil {
+		return nil
+	}
+	prog := m.recv.Parent().Prog
+	return implementations(prog, m.recvType(), m.method, dataOf(prog).interfaceTypes())
+}
+
+// wrapperForm returns the form of a call of fn if fn is a bound method wrapper or a thunk, or FormDirect otherwise.
+func wrapperForm(fn *ssa.Function) CallForm {
+	switch {
+	case strings.HasPrefix(fn.Synthetic, "bound method wrapper for "):
+		return FormMethodValue
+	case strings.HasPrefix(fn.Synthetic, "thunk for "):
+		return FormMethodExpr
+	}
+	return FormDirect
+}
+
+// wrappedMethod returns the concrete method that the bound method wrapper or thunk fn calls.
+func wrappedMethod(fn *ssa.Function) *ssa.Function {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if site, ok := instr.(ssa.CallInstruction); ok {
+				if callee := site.Common().StaticCallee(); callee != nil {
+					return declaredMethod(callee)
+				}
+			}
+		}
+	}
+	return declaredMethod(fn.Prog.FuncValue(fn.Object().(*types.Func)))
 }
 
 type CallInfo interface {
 	marker()
+	callee() *callee
 	Name() string
+	// Arg returns the idx-th argument as it is passed in SSA, excluding the receiver. It panics if idx is out of range.
 	Arg(idx int) ssa.Value
+	// ArgsLen returns the number of arguments as they are passed in SSA, excluding the receiver.
 	ArgsLen() int
+	// Args returns the logical arguments excluding the receiver.
+	// The implicit slice built for a variadic parameter is unpacked into the arguments it holds,
+	// unless the call passes an existing slice with "...".
+	Args() []ssa.Value
+	// ArgOK returns the idx-th logical argument of Args and whether it exists.
+	ArgOK(idx int) (ssa.Value, bool)
+	// ArgByName returns the argument passed for the parameter of the callee declared with name.
+	// The parameters of generic functions are looked up in their origin.
+	// It is false if the parameter is unknown, e.g. for builtins or unnamed parameters of function values.
+	ArgByName(name string) (ssa.Value, bool)
+	// TypeArgs returns the type arguments of the instantiation that is called, or nil if the callee is not generic.
+	// For methods, these are the type arguments of the receiver type.
+	TypeArgs() []types.Type
+	// NameAs returns the name of the callee in the given form. Name returns the default form of each kind.
+	NameAs(form NameForm) string
+	// Form returns the syntactic form of the call. Calls of method values and method expressions are
+	// described by the method they call, so that they match the same patterns as direct calls.
+	Form() CallForm
 	Match(namePattern string) bool
 }
 
+// NameForm is the form in which CallInfo.NameAs renders the name of a generic callee.
+type NameForm int
+
+const (
+	// NameOrigin renders the generic origin, e.g. "pkg.Map" or "(pkg.Box[T]).Get".
+	NameOrigin NameForm = iota
+	// NameInstance renders the instantiation with its type arguments, e.g. "pkg.Map[int, string]" or "(pkg.Box[int]).Get".
+	NameInstance
+)
+
 func (s *StaticMethodCall) marker()          {}
 func (d *DynamicMethodCall) marker()         {}
 func (b *BuiltinDynamicMethodCall) marker()  {}
@@ -312,11 +638,21 @@ func GetCallInfo(common *ssa.CallCommon) CallInfo {
 			// e.g. len, append, etc.
 			return NewBuiltinStaticFunctionCall(common)
 		case *ssa.MakeClosure:
+			if wrapperForm(fn.Fn.(*ssa.Function)) == FormMethodValue {
+				// method value call
+				// e.g. f := x.String; f()
+				return newWrappedMethodCall(common)
+			}
 			// static function closure call
 			// e.g. func() { ... }()
 			// names are described as xxxFunc$1()
 			return NewStaticFunctionClosureCall(common)
 		case *ssa.Function:
+			if wrapperForm(fn) != FormDirect {
+				// method expression call or method value call
+				// e.g. T.String(x)
+				return newWrappedMethodCall(common)
+			}
 			if fn.Signature.Recv() == nil {
 				// static function call
 				return NewStaticFunctionCall(common)
@@ -331,6 +667,141 @@ func GetCallInfo(common *ssa.CallCommon) CallInfo {
 	}
 }
 
+// newWrappedMethodCall classifies a call through a bound method wrapper or a thunk by the method it wraps.
+func newWrappedMethodCall(common *ssa.CallCommon) CallInfo {
+	m := newMethodCall(common)
+	switch {
+	case m.fn != nil:
+		return &StaticMethodCall{CallCommon: *common, m: m}
+	case m.method.Pkg() == nil:
+		return &BuiltinDynamicMethodCall{CallCommon: *common, m: m}
+	default:
+		return &DynamicMethodCall{CallCommon: *common, m: m}
+	}
+}
+
+// FuncCallInfo returns the CallInfo of a static call to fn.
+// Synthetic method wrappers such as bound methods are described by the method they wrap.
+func FuncCallInfo(fn *ssa.Function) CallInfo {
+	return GetCallInfo(&ssa.CallCommon{Value: declaredMethod(fn)})
+}
+
+func logicalArgs(common *ssa.CallCommon, skip int) []ssa.Value {
+	if len(common.Args) < skip {
+		return []ssa.Value{}
+	}
+	args := slices.Clone(common.Args[skip:])
+	if sig := common.Signature(); sig == nil || !sig.Variadic() || len(args) == 0 {
+		return args
+	}
+	if elems, ok := variadicArgs(args[len(args)-1]); ok {
+		return append(args[:len(args)-1], elems...)
+	}
+	return args
+}
+
+// variadicArgs returns the arguments held by the implicit slice v built for a variadic parameter.
+func variadicArgs(v ssa.Value) ([]ssa.Value, bool) {
+	switch v := v.(type) {
+	case *ssa.Const:
+		if v.IsNil() {
+			return []ssa.Value{}, true // no variadic arguments
+		}
+	case *ssa.Slice:
+		alloc, ok := v.X.(*ssa.Alloc)
+		if !ok || alloc.Comment != "varargs" {
+			return nil, false
+		}
+		arr := alloc.Type().(*types.Pointer).Elem().(*types.Array)
+		elems := make([]ssa.Value, arr.Len())
+		for _, ref := range *alloc.Referrers() {
+			if ia, ok := ref.(*ssa.IndexAddr); ok {
+				if c, ok := ia.Index.(*ssa.Const); ok {
+					for _, iaRef := range *ia.Referrers() {
+						if store, ok := iaRef.(*ssa.Store); ok && store.Addr == ia {
+							elems[c.Int64()] = store.Val
+						}
+					}
+				}
+			}
+		}
+		if slices.Contains(elems, nil) {
+			return nil, false
+		}
+		return elems, true
+	}
+	return nil, false
+}
+
+// argByName returns the argument of common passed for the parameter named name in sig, skipping the first skip arguments.
+// The variadic parameter refers to the slice passed for it.
+func argByName(common *ssa.CallCommon, skip int, sig *types.Signature, name string) (ssa.Value, bool) {
+	if sig == nil || name == "" || name == "_" || len(common.Args) < skip {
+		return nil, false
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Params().At(i).Name() == name {
+			return argOK(common.Args[skip:], i)
+		}
+	}
+	return nil, false
+}
+
+func originSignature(fn *ssa.Function) *types.Signature {
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+	return fn.Signature
+}
+
+func funcSignature(fn *types.Func) *types.Signature {
+	if fn == nil {
+		return nil
+	}
+	return fn.Origin().Type().(*types.Signature)
+}
+
+func namedTypeArgs(t types.Type) []types.Type {
+	named, ok := t.(*types.Named)
+	if !ok || named.TypeArgs() == nil {
+		return nil
+	}
+	res := make([]types.Type, 0, named.TypeArgs().Len())
+	for i := 0; i < named.TypeArgs().Len(); i++ {
+		res = append(res, named.TypeArgs().At(i))
+	}
+	return res
+}
+
+// originTypeString returns the generic origin of named with the names of its type parameters, e.g. "pkg.List[T]".
+func originTypeString(named *types.Named) string {
+	tparams := named.Origin().TypeParams()
+	names := make([]string, 0, tparams.Len())
+	for i := 0; i < tparams.Len(); i++ {
+		names = append(names, tparams.At(i).Obj().Name())
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return obj.Name() + "[" + strings.Join(names, ", ") + "]"
+	}
+	return obj.Pkg().Path() + "." + obj.Name() + "[" + strings.Join(names, ", ") + "]"
+}
+
+func typeArgsString(targs []types.Type) string {
+	strs := make([]string, 0, len(targs))
+	for _, t := range targs {
+		strs = append(strs, types.TypeString(t, nil))
+	}
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+func argOK(args []ssa.Value, idx int) (ssa.Value, bool) {
+	if idx < 0 || idx >= len(args) {
+		return nil, false
+	}
+	return args[idx], true
+}
+
 func InstrToCallCommon(instr ssa.Instruction) (*ssa.CallCommon, bool) {
 	switch i := instr.(type) {
 	case ssa.CallInstruction:
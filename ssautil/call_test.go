@@ -54,6 +54,9 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call.Foo", staticMethodCalls[0].Recv().Type().String())
 	assert.Equal(t, "String", staticMethodCalls[0].Method().Name())
 	assert.Panics(t, func() { staticMethodCalls[0].Arg(0) })
+	assert.Empty(t, staticMethodCalls[0].Args())
+	_, ok := staticMethodCalls[0].ArgOK(0)
+	assert.False(t, ok)
 	assert.Equal(t, "(*github.com/haijima/analysisutil/ssautil/testdata/src/call.Fizz).String", staticMethodCalls[1].Name())
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call", staticMethodCalls[1].Pkg().Path())
 	assert.Equal(t, "*github.com/haijima/analysisutil/ssautil/testdata/src/call.Fizz", staticMethodCalls[1].Recv().Type().String())
@@ -106,6 +109,17 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.True(t, dynamicMethodCalls[1].Match("*.Barer.*"))
 	assert.True(t, dynamicMethodCalls[1].Match("*.*.*"))
 
+	impls := dynamicMethodCalls[0].Implementations()
+	assert.Equal(t, 2, len(impls))
+	assert.Equal(t, "(*github.com/haijima/analysisutil/ssautil/testdata/src/call.Fizz).String", impls[0].String())
+	assert.Equal(t, "(github.com/haijima/analysisutil/ssautil/testdata/src/call.Foo).String", impls[1].String())
+	assert.True(t, dynamicMethodCalls[0].MatchImplementations("(*github.com/haijima/analysisutil/ssautil/testdata/src/call.Foo).String", ssautil.MatchAny))
+	assert.False(t, dynamicMethodCalls[0].MatchImplementations("(*github.com/haijima/analysisutil/ssautil/testdata/src/call.Foo).String", ssautil.MatchAll))
+	assert.True(t, dynamicMethodCalls[0].MatchImplementations("(*.*).String", ssautil.MatchAll))
+	impls = dynamicMethodCalls[1].Implementations()
+	assert.Equal(t, 1, len(impls))
+	assert.Equal(t, "(github.com/haijima/analysisutil/ssautil/testdata/src/call.B).Bar", impls[0].String())
+
 	assert.Equal(t, 1, len(builtinDynamicMethodCalls))
 	assert.Equal(t, "error.Error", builtinDynamicMethodCalls[0].Name())
 	assert.Equal(t, "error", builtinDynamicMethodCalls[0].Recv().Type().String())
@@ -122,10 +136,24 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.Equal(t, "fmt", staticFunctionCalls[0].Pkg().Path())
 	assert.Equal(t, "Println", staticFunctionCalls[0].Func().Name())
 	assert.NotNil(t, staticFunctionCalls[0].Arg(0))
+	assert.Equal(t, 1, len(staticFunctionCalls[0].Args())) // unpacked from the implicit []any
+	arg, ok := staticFunctionCalls[0].ArgOK(0)
+	assert.True(t, ok)
+	assert.IsType(t, &ssa.MakeInterface{}, arg)
+	_, ok = staticFunctionCalls[0].ArgOK(1)
+	assert.False(t, ok)
+	arg, ok = staticFunctionCalls[0].ArgByName("a") // variadic parameter
+	assert.True(t, ok)
+	assert.IsType(t, &ssa.Slice{}, arg)
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call.foo", staticFunctionCalls[1].Name())
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call", staticFunctionCalls[1].Pkg().Path())
 	assert.Equal(t, "foo", staticFunctionCalls[1].Func().Name())
 	assert.NotNil(t, staticFunctionCalls[1].Arg(0))
+	arg, ok = staticFunctionCalls[1].ArgByName("t") // generic parameter
+	assert.True(t, ok)
+	assert.Equal(t, staticFunctionCalls[1].Arg(0), arg)
+	_, ok = staticFunctionCalls[1].ArgByName("s")
+	assert.False(t, ok)
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call.anonymousStaticFunc$1", staticFunctionCalls[2].Name())
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call", staticFunctionCalls[2].Pkg().Path())
 	assert.Equal(t, "anonymousStaticFunc$1", staticFunctionCalls[2].Func().Name())
@@ -134,6 +162,9 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/call", staticFunctionCalls[3].Pkg().Path())
 	assert.Equal(t, "getCallable", staticFunctionCalls[3].Func().Name())
 	assert.Panics(t, func() { staticFunctionCalls[3].Arg(0) })
+	_, ok = staticFunctionCalls[3].ArgOK(0)
+	assert.False(t, ok)
+	assert.Empty(t, staticFunctionCalls[3].Args())
 
 	assert.True(t, staticFunctionCalls[1].Match("github.com/haijima/analysisutil/ssautil/testdata/src/call.foo"))
 	assert.True(t, staticFunctionCalls[1].Match("*.foo"))
@@ -143,6 +174,9 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.Equal(t, 1, len(builtinStaticFunctionCalls))
 	assert.Equal(t, "append", builtinStaticFunctionCalls[0].Name())
 	assert.Equal(t, "append", builtinStaticFunctionCalls[0].Func().Name())
+	assert.Equal(t, 2, len(builtinStaticFunctionCalls[0].Args()))
+	_, ok = builtinStaticFunctionCalls[0].ArgByName("slice")
+	assert.False(t, ok)
 
 	assert.True(t, builtinStaticFunctionCalls[0].Match("append"))
 	assert.True(t, builtinStaticFunctionCalls[0].Match("*"))
@@ -164,8 +198,26 @@ func TestGetFuncInfo(t *testing.T) {
 	assert.Panics(t, func() { dynamicFunctionCalls[1].Arg(0) })
 	assert.Equal(t, "getCallable", dynamicFunctionCalls[2].Name())
 	assert.NotNil(t, dynamicFunctionCalls[2].Arg(0))
+	arg, ok = dynamicFunctionCalls[2].ArgByName("num")
+	assert.True(t, ok)
+	assert.Equal(t, dynamicFunctionCalls[2].Arg(0), arg)
 
-	assert.False(t, dynamicFunctionCalls[0].Match("fn")) // Always false
+	assert.False(t, dynamicFunctionCalls[0].Match("fn")) // fn is not the name of a target
+	targets, ok := dynamicFunctionCalls[0].Targets()
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, "main$1", targets[0].Name())
+	assert.True(t, dynamicFunctionCalls[0].Match("*.main$1"))
+	assert.True(t, dynamicFunctionCalls[0].MatchTargets("github.com/haijima/analysisutil/ssautil/testdata/src/call.*", ssautil.MatchAny))
+	targets, ok = dynamicFunctionCalls[1].Targets()
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, "init$1", targets[0].Name())
+	targets, ok = dynamicFunctionCalls[2].Targets()
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, "getCallable$1", targets[0].Name())
+	assert.True(t, dynamicFunctionCalls[2].Match("*.getCallable$1"))
 }
 
 func GetInstructions(t *testing.T, dir string, patterns ...string) ([]ssa.Instruction, error) {
@@ -195,3 +247,114 @@ func GetInstructions(t *testing.T, dir string, patterns ...string) ([]ssa.Instru
 	}
 	return result, nil
 }
+
+func TestGetCallInfo_TypeArgs(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/generics", "./...")
+	require.NoError(t, err)
+
+	calls := make([]ssautil.CallInfo, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "calls" {
+			calls = append(calls, ssautil.GetCallInfo(call.Common()))
+		}
+	}
+	require.Equal(t, 5, len(calls))
+
+	const pkg = "github.com/haijima/analysisutil/ssautil/testdata/src/generics"
+	tests := []struct {
+		name     string
+		origin   string
+		instance string
+		typeArgs []string
+	}{
+		{pkg + ".Map", pkg + ".Map", pkg + ".Map[int, string]", []string{"int", "string"}},
+		{pkg + ".Map", pkg + ".Map", pkg + ".Map[string, int]", []string{"string", "int"}},
+		{"slices.Contains", "slices.Contains", "slices.Contains[[]string, string]", []string{"[]string", "string"}},
+		{"(" + pkg + ".Box[int]).Get", "(" + pkg + ".Box[T]).Get", "(" + pkg + ".Box[int]).Get", []string{"int"}},
+		{pkg + ".get", pkg + ".get", pkg + ".get", []string{}},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.name, calls[i].Name())
+		assert.Equal(t, tt.origin, calls[i].NameAs(ssautil.NameOrigin))
+		assert.Equal(t, tt.instance, calls[i].NameAs(ssautil.NameInstance))
+		typeArgs := make([]string, 0)
+		for _, targ := range calls[i].TypeArgs() {
+			typeArgs = append(typeArgs, targ.String())
+		}
+		assert.Equal(t, tt.typeArgs, typeArgs)
+	}
+
+	assert.True(t, calls[0].Match("*.Map[int]"))
+	assert.True(t, calls[0].Match("*.Map[int, string]"))
+	assert.False(t, calls[1].Match("*.Map[int]"))
+	assert.True(t, calls[2].Match("slices.Contains[[]string]"))
+	assert.False(t, calls[2].Match("slices.Contains[[]int]"))
+	assert.True(t, calls[3].Match("(*.Box[int]).Get"))
+	assert.False(t, calls[3].Match("(*.Box[string]).Get"))
+
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "get" {
+			c := ssautil.GetCallInfo(call.Common())
+			assert.Equal(t, pkg+".Getter[string].Get", c.Name())
+			assert.Equal(t, pkg+".Getter[T].Get", c.NameAs(ssautil.NameOrigin))
+			assert.Equal(t, "string", c.TypeArgs()[0].String())
+			assert.True(t, c.Match("*.Getter[string].Get"))
+		}
+	}
+}
+
+func TestGetCallInfo_MethodForms(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/methodvalue", "./...")
+	require.NoError(t, err)
+
+	calls := make([]ssautil.CallInfo, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			calls = append(calls, ssautil.GetCallInfo(call.Common()))
+		}
+	}
+	require.Equal(t, 9, len(calls))
+
+	const pkg = "github.com/haijima/analysisutil/ssautil/testdata/src/methodvalue"
+	tests := []struct {
+		name string
+		form ssautil.CallForm
+		arg  string
+	}{
+		{"(" + pkg + ".T).M", ssautil.FormDirect, `"a":string`},
+		{"(" + pkg + ".T).M", ssautil.FormMethodValue, `"b":string`},
+		{"(" + pkg + ".T).M", ssautil.FormMethodExpr, `"c":string`},
+		{"(*" + pkg + ".T).P", ssautil.FormMethodExpr, `"d":string`},
+		{pkg + ".I.M", ssautil.FormMethodValue, `"e":string`},
+		{pkg + ".I.M", ssautil.FormMethodExpr, `"f":string`},
+		{pkg + ".J.M", ssautil.FormDirect, `"g":string`},
+	}
+	for i, tt := range tests {
+		assert.Equal(t, tt.name, calls[i].Name())
+		assert.Equal(t, tt.form, calls[i].Form())
+		assert.Equal(t, 1, calls[i].ArgsLen())
+		assert.Equal(t, tt.arg, calls[i].Arg(0).String())
+		arg, ok := calls[i].ArgByName("s")
+		assert.True(t, ok)
+		assert.Equal(t, calls[i].Arg(0), arg)
+	}
+
+	for _, i := range []int{0, 1, 2} {
+		require.IsType(t, &ssautil.StaticMethodCall{}, calls[i])
+		assert.Equal(t, "parameter t : T", calls[i].(*ssautil.StaticMethodCall).Recv().String())
+		assert.Equal(t, "M", calls[i].(*ssautil.StaticMethodCall).Func().Name())
+		assert.True(t, calls[i].Match("("+pkg+".T).M"))
+	}
+	for _, i := range []int{4, 5, 6} {
+		require.IsType(t, &ssautil.DynamicMethodCall{}, calls[i])
+		assert.NotNil(t, calls[i].(*ssautil.DynamicMethodCall).Recv())
+		assert.True(t, calls[i].Match(pkg+".I.M"))
+	}
+	assert.True(t, calls[6].Match(pkg+".J.M"))
+	assert.False(t, calls[5].Match(pkg+".J.M"))
+	assert.Equal(t, "method value", calls[1].Form().String())
+
+	// apply(pt.P) passes a method value that is called as f("h")
+	require.IsType(t, &ssautil.DynamicFunctionCall{}, calls[8])
+	assert.True(t, calls[8].Match("(*"+pkg+".T).P"))
+}
@@ -0,0 +1,319 @@
+package ssautil
+
+import (
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	xssautil "golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// CallGraphAlgorithm selects how dynamic calls are resolved by BuildCallGraph.
+type CallGraphAlgorithm int
+
+const (
+	// CHA resolves dynamic method calls to the methods of every named type in the program
+	// that implements the interface (Class Hierarchy Analysis).
+	CHA CallGraphAlgorithm = iota
+	// RTA resolves dynamic method calls to the methods of the types that are converted to an interface in
+	// the functions reachable from the roots of the program, and dynamic function calls to the functions used as
+	// values in them (Rapid Type Analysis). The reachable functions are found together with the types.
+	// The roots are the main and init functions of the main packages,
+	// or the init functions and the exported functions and methods of every package if there is no main package.
+	RTA
+)
+
+// CallEdge is a caller→callee edge of a call graph.
+type CallEdge struct {
+	Caller *ssa.Function
+	Callee *ssa.Function
+	Site   ssa.CallInstruction
+	Call   CallInfo
+	Pos    *Posx
+}
+
+// CallGraph is a call graph built from the call sites classified by GetCallInfo.
+type CallGraph struct {
+	Prog      *ssa.Program
+	Algorithm CallGraphAlgorithm
+
+	out map[*ssa.Function][]*CallEdge
+	in  map[*ssa.Function][]*CallEdge
+}
+
+// BuildCallGraph builds the call graph of all functions in prog.
+// Static calls are resolved directly, dynamic method calls through the method sets of the types selected by algo,
+// and dynamic function calls to the address-taken functions with an identical signature.
+// Calls to built-in functions are not included.
+func BuildCallGraph(prog *ssa.Program, algo CallGraphAlgorithm) *CallGraph {
+	g := &CallGraph{Prog: prog, Algorithm: algo, out: make(map[*ssa.Function][]*CallEdge), in: make(map[*ssa.Function][]*CallEdge)}
+
+	funcs := make([]*ssa.Function, 0)
+	for fn := range xssautil.AllFunctions(prog) {
+		funcs = append(funcs, fn)
+	}
+	slices.SortFunc(funcs, func(a, b *ssa.Function) int { return strings.Compare(a.String(), b.String()) })
+
+	var candidates []types.Type
+	var addrTaken []*ssa.Function
+	if algo == RTA {
+		candidates, addrTaken = rapidTypes(prog, funcs)
+	} else {
+		candidates, addrTaken = namedTypes(prog), addressTakenFuncs(funcs)
+	}
+
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				site, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				c := GetCallInfo(site.Common())
+				for _, callee := range g.callees(c, candidates, addrTaken) {
+					e := &CallEdge{Caller: fn, Callee: callee, Site: site, Call: c, Pos: NewPos(fn, site.Pos())}
+					g.out[fn] = append(g.out[fn], e)
+					g.in[callee] = append(g.in[callee], e)
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *CallGraph) callees(c CallInfo, candidates []types.Type, addrTaken []*ssa.Function) []*ssa.Function {
+	switch c := c.(type) {
+	case *StaticFunctionCall:
+		return []*ssa.Function{c.Value.(*ssa.Function)}
+	case *StaticMethodCall:
+		return []*ssa.Function{c.Func()}
+	case *StaticFunctionClosureCall:
+		return []*ssa.Function{c.Func()}
+	case *DynamicMethodCall:
+		return implementations(g.Prog, c.m.recvType(), c.Method(), candidates)
+	case *BuiltinDynamicMethodCall:
+		return implementations(g.Prog, c.m.recvType(), c.Method(), candidates)
+	case *DynamicFunctionCall:
+		res := make([]*ssa.Function, 0)
+		for _, fn := range addrTaken {
+			if types.Identical(fn.Signature, c.Signature()) {
+				res = append(res, fn)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+// Callees returns the outgoing edges of fn in the order of the call sites.
+func (g *CallGraph) Callees(fn *ssa.Function) []*CallEdge {
+	return g.out[fn]
+}
+
+// Callers returns the incoming edges of fn.
+func (g *CallGraph) Callers(fn *ssa.Function) []*CallEdge {
+	return g.in[fn]
+}
+
+// implementations returns the concrete methods that a dynamic call of method on a value of type recv may dispatch to,
+// considering only the given candidate types.
+func implementations(prog *ssa.Program, recv types.Type, method *types.Func, candidates []types.Type) []*ssa.Function {
+	iface, ok := recv.Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+	res := make([]*ssa.Function, 0)
+	for _, t := range candidates {
+		if !types.Implements(t, iface) {
+			continue
+		}
+		if sel := prog.MethodSets.MethodSet(t).Lookup(method.Pkg(), method.Name()); sel != nil {
+			if fn := declaredMethod(prog.MethodValue(sel)); fn != nil && !slices.Contains(res, fn) {
+				res = append(res, fn)
+			}
+		}
+	}
+	return res
+}
+
+// declaredMethod returns the declared method that a synthetic method wrapper such as (*T).M for (T).M delegates to.
+func declaredMethod(fn *ssa.Function) *ssa.Function {
+	if fn == nil || !strings.HasPrefix(fn.Synthetic, "wrapper for ") {
+		return fn
+	}
+	if obj, ok := fn.Object().(*types.Func); ok {
+		if declared := fn.Prog.FuncValue(obj); declared != nil {
+			return declared
+		}
+	}
+	return fn
+}
+
+// namedTypes returns the non-interface named types declared in prog and their pointer types,
+// including the types declared in functions other than generic ones.
+func namedTypes(prog *ssa.Program) []types.Type {
+	res := make([]types.Type, 0)
+	var walk func(s *types.Scope)
+	walk = func(s *types.Scope) {
+		for _, name := range s.Names() {
+			if _, ok := s.Lookup(name).Type().(*types.TypeParam); ok {
+				return // a local type of a generic function depends on its type parameters
+			}
+		}
+		for _, name := range s.Names() {
+			tn, ok := s.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			if named, ok := tn.Type().(*types.Named); ok && !types.IsInterface(named) && named.TypeParams().Len() == 0 {
+				res = append(res, named, types.NewPointer(named))
+			}
+		}
+		for i := 0; i < s.NumChildren(); i++ {
+			walk(s.Child(i))
+		}
+	}
+	for _, pkg := range prog.AllPackages() {
+		walk(pkg.Pkg.Scope())
+	}
+	slices.SortFunc(res, func(a, b types.Type) int { return strings.Compare(a.String(), b.String()) })
+	return res
+}
+
+// rapidTypes returns the non-interface types converted to an interface and the functions used as values
+// in the functions reachable from the roots of funcs, resolving the dynamic calls in the reachable functions
+// with the types and the functions found so far until no more functions are reached.
+func rapidTypes(prog *ssa.Program, funcs []*ssa.Function) ([]types.Type, []*ssa.Function) {
+	g := &CallGraph{Prog: prog}
+	reached := make(map[*ssa.Function]bool)
+	queue := make([]*ssa.Function, 0)
+	reach := func(fn *ssa.Function) {
+		if !reached[fn] {
+			reached[fn] = true
+			queue = append(queue, fn)
+		}
+	}
+	for _, fn := range rtaRoots(funcs) {
+		reach(fn)
+	}
+
+	var seen typeutil.Map
+	tys := make([]types.Type, 0)
+	addrTaken := make([]*ssa.Function, 0)
+	dynamic := make([]CallInfo, 0)
+	var buf [10]*ssa.Value
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			fn := queue[0]
+			queue = queue[1:]
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					if mi, ok := instr.(*ssa.MakeInterface); ok {
+						if t := mi.X.Type(); !types.IsInterface(t) && seen.Set(t, true) == nil {
+							tys = append(tys, t)
+						}
+					}
+					ops := instr.Operands(buf[:0])
+					if site, ok := instr.(ssa.CallInstruction); ok {
+						switch c := GetCallInfo(site.Common()).(type) {
+						case *StaticFunctionCall, *StaticMethodCall, *StaticFunctionClosureCall:
+							for _, callee := range g.callees(c, nil, nil) {
+								reach(callee)
+							}
+						case *DynamicMethodCall, *BuiltinDynamicMethodCall, *DynamicFunctionCall:
+							dynamic = append(dynamic, c)
+						}
+						if !site.Common().IsInvoke() {
+							ops = ops[1:] // skip the callee
+						}
+					}
+					for _, op := range ops {
+						if f, ok := (*op).(*ssa.Function); ok && !slices.Contains(addrTaken, f) {
+							addrTaken = append(addrTaken, f)
+						}
+					}
+				}
+			}
+		}
+		// the types and functions found may add callees to the dynamic calls
+		for _, c := range dynamic {
+			for _, callee := range g.callees(c, tys, addrTaken) {
+				reach(callee)
+			}
+		}
+	}
+	slices.SortFunc(tys, func(a, b types.Type) int { return strings.Compare(a.String(), b.String()) })
+	return tys, addrTaken
+}
+
+// rtaRoots returns the roots of RTA among funcs.
+// These are the main and init functions of the main packages if there are any,
+// or the init functions and the exported functions and methods otherwise.
+func rtaRoots(funcs []*ssa.Function) []*ssa.Function {
+	mains := make([]*ssa.Function, 0)
+	exported := make([]*ssa.Function, 0)
+	for _, fn := range funcs {
+		if fn.Pkg == nil || fn.Parent() != nil || fn.Synthetic != "" && fn.Synthetic != "package initializer" {
+			continue
+		}
+		isMain := fn.Pkg.Pkg.Name() == "main"
+		switch {
+		case fn.Name() == "init":
+			mains = append(mains, fn)
+			exported = append(exported, fn)
+		case isMain && fn.Name() == "main" && fn.Signature.Recv() == nil:
+			mains = append(mains, fn)
+		case fn.Object() != nil && fn.Object().Exported():
+			exported = append(exported, fn)
+		}
+	}
+	if slices.ContainsFunc(mains, func(fn *ssa.Function) bool { return fn.Name() == "main" }) {
+		return mains
+	}
+	return exported
+}
+
+// makeInterfaceTypes returns the non-interface types converted to an interface in funcs.
+func makeInterfaceTypes(funcs []*ssa.Function) []types.Type {
+	var seen typeutil.Map
+	res := make([]types.Type, 0)
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if mi, ok := instr.(*ssa.MakeInterface); ok {
+					if t := mi.X.Type(); !types.IsInterface(t) && seen.Set(t, true) == nil {
+						res = append(res, t)
+					}
+				}
+			}
+		}
+	}
+	slices.SortFunc(res, func(a, b types.Type) int { return strings.Compare(a.String(), b.String()) })
+	return res
+}
+
+// addressTakenFuncs returns the functions in funcs that are used as values, i.e. other than as the callee of a call.
+func addressTakenFuncs(funcs []*ssa.Function) []*ssa.Function {
+	seen := make(map[*ssa.Function]bool)
+	res := make([]*ssa.Function, 0)
+	var buf [10]*ssa.Value
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ops := instr.Operands(buf[:0])
+				if site, ok := instr.(ssa.CallInstruction); ok && !site.Common().IsInvoke() {
+					ops = ops[1:] // skip the callee
+				}
+				for _, op := range ops {
+					if f, ok := (*op).(*ssa.Function); ok && !seen[f] {
+						seen[f] = true
+						res = append(res, f)
+					}
+				}
+			}
+		}
+	}
+	return res
+}
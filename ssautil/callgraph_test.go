@@ -0,0 +1,70 @@
+package ssautil_test
+
+import (
+	"testing"
+
+	"github.com/haijima/analysisutil/ssautil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestBuildCallGraph(t *testing.T) {
+	prog, err := ssautil.LoadProgram("./testdata/src/callgraph", "./...")
+	require.NoError(t, err)
+	pkg := prog.Packages[0].Pkg
+
+	calleeNames := func(g *ssautil.CallGraph, fn *ssa.Function) []string {
+		names := make([]string, 0)
+		for _, e := range g.Callees(fn) {
+			names = append(names, e.Callee.String())
+		}
+		return names
+	}
+
+	cha := ssautil.BuildCallGraph(prog.Prog, ssautil.CHA)
+	assert.Equal(t, []string{
+		"(github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Circle).Area",
+		"(*github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Rect).Area",
+		"(github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Square).Area",
+		"(*github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.labeled).Area",
+		"(github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.labeled).Area",
+	}, calleeNames(cha, pkg.Func("sum")))
+	assert.Equal(t, []string{"github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.double"}, calleeNames(cha, pkg.Func("apply")))
+
+	// Circle and labeled are only converted to Shape in the unreachable function unused
+	rta := ssautil.BuildCallGraph(prog.Prog, ssautil.RTA)
+	edges := rta.Callees(pkg.Func("sum"))
+	require.Equal(t, 2, len(edges))
+	assert.Equal(t, "(*github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Rect).Area", edges[0].Callee.String())
+	assert.Equal(t, "(github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Square).Area", edges[1].Callee.String())
+	assert.IsType(t, &ssautil.DynamicMethodCall{}, edges[0].Call)
+	assert.Equal(t, "main.go:37:18", edges[0].Pos.PositionString())
+	assert.Same(t, pkg.Func("sum"), edges[0].Caller)
+
+	callers := rta.Callers(pkg.Func("double"))
+	require.Equal(t, 1, len(callers))
+	assert.Same(t, pkg.Func("apply"), callers[0].Caller)
+	assert.IsType(t, &ssautil.DynamicFunctionCall{}, callers[0].Call)
+}
+
+func TestCallGraph_FindReachableCalls(t *testing.T) {
+	prog, err := ssautil.LoadProgram("./testdata/src/callgraph", "./...")
+	require.NoError(t, err)
+	pkg := prog.Packages[0].Pkg
+	g := ssautil.BuildCallGraph(prog.Prog, ssautil.RTA)
+
+	area, printLine := ssautil.MustParsePattern("*.Shape.Area"), ssautil.MustParsePattern("fmt.Println")
+	calls := g.FindReachableCalls(pkg.Func("main"), area, printLine)
+	require.Equal(t, 2, len(calls))
+	assert.Same(t, printLine, calls[0].Pattern)
+	assert.Equal(t, 1, len(calls[0].Path))
+	assert.Same(t, area, calls[1].Pattern)
+	assert.Equal(t, "github.com/haijima/analysisutil/ssautil/testdata/src/callgraph.Shape.Area", calls[1].Call.Name())
+	require.Equal(t, 2, len(calls[1].Path))
+	assert.Equal(t, "main.go:30:14", calls[1].Path[0].PositionString())
+	assert.Equal(t, "main.go:37:18", calls[1].Path[1].PositionString())
+
+	assert.True(t, g.Reaches(pkg.Func("main"), area))
+	assert.False(t, g.Reaches(pkg.Func("double"), printLine))
+}
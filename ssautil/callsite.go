@@ -0,0 +1,124 @@
+package ssautil
+
+import (
+	"golang.org/x/tools/go/ssa"
+)
+
+// CallMode describes how a call site makes its call.
+type CallMode int
+
+const (
+	// CallImmediate is an ordinary call that is made when it is reached.
+	CallImmediate CallMode = iota
+	// CallDeferred is a call of a defer statement, made when the function returns or panics.
+	CallDeferred
+	// CallGo is a call of a go statement, made in a new goroutine.
+	CallGo
+	// CallInDeferred is an ordinary call in a function literal that is only ever deferred,
+	// so it is made while the deferred calls run (ssa.RunDefers) rather than when it is reached.
+	CallInDeferred
+)
+
+func (m CallMode) String() string {
+	switch m {
+	case CallImmediate:
+		return "immediate"
+	case CallDeferred:
+		return "defer"
+	case CallGo:
+		return "go"
+	case CallInDeferred:
+		return "in deferred"
+	default:
+		return "unknown"
+	}
+}
+
+// CallSite is a CallInfo together with the call instruction and how the call is made.
+// Patterns prefixed with "go " or "defer " only match call sites of the respective mode.
+type CallSite struct {
+	CallInfo
+	Instr ssa.CallInstruction
+	Mode  CallMode
+}
+
+// GetCallSite classifies the call made by instr.
+func GetCallSite(instr ssa.CallInstruction) *CallSite {
+	mode := CallImmediate
+	switch instr.(type) {
+	case *ssa.Defer:
+		mode = CallDeferred
+	case *ssa.Go:
+		mode = CallGo
+	default:
+		if onlyDeferred(instr.Parent()) {
+			mode = CallInDeferred
+		}
+	}
+	return &CallSite{CallInfo: GetCallInfo(instr.Common()), Instr: instr, Mode: mode}
+}
+
+// InstrToCallSite is like InstrToCallCommon but returns the CallSite of instr.
+func InstrToCallSite(instr ssa.Instruction) (*CallSite, bool) {
+	switch i := instr.(type) {
+	case ssa.CallInstruction:
+		return GetCallSite(i), true
+	case *ssa.Extract:
+		if call, ok := i.Tuple.(*ssa.Call); ok {
+			return GetCallSite(call), true
+		}
+	}
+	return nil, false
+}
+
+func (s *CallSite) Match(namePattern string) bool {
+	return matchPattern(s, namePattern)
+}
+
+// onlyDeferred reports whether fn is a function literal that is only called by defer statements,
+// either directly or from other such function literals. It is false if the function value escapes.
+func onlyDeferred(fn *ssa.Function) bool {
+	if fn == nil || fn.Parent() == nil {
+		return false
+	}
+	sites := make([]ssa.CallInstruction, 0)
+	for _, b := range fn.Parent().Blocks {
+		for _, instr := range b.Instrs {
+			if mc, ok := instr.(*ssa.MakeClosure); ok {
+				if mc.Fn != fn {
+					continue
+				}
+				for _, ref := range *mc.Referrers() {
+					site, ok := ref.(ssa.CallInstruction)
+					if !ok || site.Common().Value != mc {
+						return false // the closure escapes
+					}
+					sites = append(sites, site)
+				}
+				continue
+			}
+			for _, op := range instr.Operands(nil) {
+				if *op != fn {
+					continue
+				}
+				site, ok := instr.(ssa.CallInstruction)
+				if !ok || site.Common().Value != fn {
+					return false // the function escapes
+				}
+				sites = append(sites, site)
+			}
+		}
+	}
+	for _, site := range sites {
+		switch site.(type) {
+		case *ssa.Defer:
+		case *ssa.Call:
+			if !onlyDeferred(site.Parent()) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return len(sites) > 0
+}
@@ -0,0 +1,62 @@
+package ssautil_test
+
+import (
+	"testing"
+
+	"github.com/haijima/analysisutil/ssautil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCallSite(t *testing.T) {
+	prog, err := ssautil.LoadProgram("./testdata/src/callmode", "./...")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(prog.Packages))
+
+	sites := make([]*ssautil.CallSite, 0)
+	for _, fn := range prog.Packages[0].SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if site, ok := ssautil.InstrToCallSite(instr); ok {
+					sites = append(sites, site)
+				}
+			}
+		}
+	}
+	require.Equal(t, 12, len(sites))
+
+	modes := []ssautil.CallMode{
+		ssautil.CallImmediate,  // wg.Add(1)
+		ssautil.CallGo,         // go work(&wg)
+		ssautil.CallGo,         // go wg.Wait()
+		ssautil.CallDeferred,   // defer wg.Wait()
+		ssautil.CallDeferred,   // defer func() { ... }()
+		ssautil.CallImmediate,  // run(f)
+		ssautil.CallImmediate,  // wg.Wait()
+		ssautil.CallInDeferred, // fmt.Println("deferred")
+		ssautil.CallImmediate,  // fmt.Println("escaped")
+		ssautil.CallDeferred,   // defer wg.Done()
+		ssautil.CallImmediate,  // fmt.Println("work")
+		ssautil.CallImmediate,  // f()
+	}
+	for i, mode := range modes {
+		assert.Equal(t, mode, sites[i].Mode, "%d: %s", i, sites[i].Name())
+	}
+	assert.Equal(t, "go", sites[2].Mode.String())
+
+	match := func(pattern string) []int {
+		res := make([]int, 0)
+		for i, site := range sites {
+			if site.Match(pattern) {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+	assert.Equal(t, []int{2, 3, 6}, match("(*sync.WaitGroup).Wait"))
+	assert.Equal(t, []int{2}, match("go (*sync.WaitGroup).Wait"))
+	assert.Equal(t, []int{3, 9}, match("defer (*sync.WaitGroup).*"))
+	assert.Equal(t, []int{1, 2}, match("go *.*|go (*.*).*"))
+	assert.Equal(t, []int{7, 8, 10}, match("fmt.Println"))
+	assert.False(t, sites[2].CallInfo.Match("go (*sync.WaitGroup).Wait")) // the call mode is unknown without CallSite
+}
@@ -0,0 +1,689 @@
+package ssautil
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+const (
+	// maxElemLists is the maximum number of possible lists of elements of a slice, an array or a map.
+	maxElemLists = 64
+	// readOnlyFuncs are the library functions that do not write to the elements of the slices passed to them,
+	// whose bodies are usually not built.
+	readOnlyFuncs = "strings.Join|bytes.Join|path.Join|path/filepath.Join|slices.Contains|slices.Index|slices.Equal|slices.Clone"
+)
+
+// MapEntry is an entry of a map.
+type MapEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// ValueToStringSlices returns the possible elements of the slice or array of strings v, in order.
+func ValueToStringSlices(v ssa.Value) ([][]string, bool) {
+	return ValueToStringSlicesWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToStringSlicesWithOptions(v ssa.Value, opts ResolveOptions) ([][]string, bool) {
+	return ValueToElemsWithOptions(v, opts, func(v ssa.Value) ([]string, bool) {
+		return ValueToStringsWithOptions(v, crossOptions(opts))
+	})
+}
+
+// ValueToIntSlices returns the possible elements of the slice or array of integers v, in order.
+func ValueToIntSlices(v ssa.Value) ([][]int, bool) {
+	return ValueToIntSlicesWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToIntSlicesWithOptions(v ssa.Value, opts ResolveOptions) ([][]int, bool) {
+	return ValueToElemsWithOptions(v, opts, func(v ssa.Value) ([]int, bool) {
+		return ValueToIntsWithOptions(v, crossOptions(opts))
+	})
+}
+
+// ValueToStringMaps returns the possible entries of the map of strings to strings v, in the order they are added.
+func ValueToStringMaps(v ssa.Value) ([][]MapEntry[string, string], bool) {
+	return ValueToStringMapsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToStringMapsWithOptions(v ssa.Value, opts ResolveOptions) ([][]MapEntry[string, string], bool) {
+	str := func(v ssa.Value) ([]string, bool) {
+		return ValueToStringsWithOptions(v, crossOptions(opts))
+	}
+	return ValueToMapEntriesWithOptions(v, opts, str, str)
+}
+
+// ValueToElemsWithOptions returns the possible elements of the slice, array or pointer to an array v, in order,
+// resolving each element with elem.
+// It follows slice and array literals through the stores to their elements, slicing with constant bounds,
+// and calls to the built-in append. Elements of an array that are not stored are zero.
+// An element with several possible values makes a list for each of them, such as an element of a global slice
+// stored in another function. It fails if the elements may be written otherwise, such as by a call.
+func ValueToElemsWithOptions[T any](v ssa.Value, opts ResolveOptions, elem func(v ssa.Value) ([]T, bool)) ([][]T, bool) {
+	return valueToElems(v, opts, elem, func(v ssa.Value) ([]int, bool) {
+		return ValueToIntsWithOptions(v, crossOptions(opts))
+	})
+}
+
+// valueToElems is ValueToElemsWithOptions resolving the bounds of slicing with ints.
+func valueToElems[T any](v ssa.Value, opts ResolveOptions, elem func(v ssa.Value) ([]T, bool), ints func(v ssa.Value) ([]int, bool)) ([][]T, bool) {
+	r := &resolver[[]T]{
+		opts: opts,
+		flattener: func(v ssa.Value, next func(v ssa.Value) ([][]T, bool)) ([][]T, bool) {
+			switch t := v.(type) {
+			case *ssa.Alloc:
+				return arrayElems(t, elem)
+			case *ssa.UnOp:
+				if alloc, ok := t.X.(*ssa.Alloc); ok && t.Op == token.MUL {
+					return arrayElems(alloc, elem)
+				}
+			case *ssa.Slice:
+				lists, ok := next(t.X)
+				if !ok {
+					break
+				}
+				return sliceElems(t, lists, ints)
+			case *ssa.ChangeType:
+				return next(t.X)
+			case *ssa.Call:
+				if b, ok := t.Call.Value.(*ssa.Builtin); !ok || b.Name() != "append" || len(t.Call.Args) == 0 {
+					break
+				}
+				lists, ok := next(t.Call.Args[0])
+				if !ok {
+					break
+				}
+				if len(t.Call.Args) == 1 {
+					return lists, true
+				}
+				if isString(t.Call.Args[1].Type()) {
+					break // append([]byte, string...)
+				}
+				elems, ok := next(t.Call.Args[1])
+				if !ok {
+					break
+				}
+				return crossLists(lists, elems)
+			}
+			return [][]T{}, false
+		},
+		mapper: func(t *ssa.Const) ([]T, bool) {
+			return []T{}, t.IsNil()
+		},
+	}
+	return r.resolve(v, resolveState{})
+}
+
+// ValueToMapEntriesWithOptions returns the possible entries of the map v, in the order they are added,
+// resolving each key with key and each value with val.
+// It follows map literals and the maps made by make through the updates of their entries,
+// and fails if the map may be updated in another function, such as a global map.
+// An entry with several possible keys or values makes a list for each of them, and so does an update that may not
+// run before the map is used. An update of a key that is already added replaces the value of its entry.
+func ValueToMapEntriesWithOptions[K comparable, V any](v ssa.Value, opts ResolveOptions, key func(v ssa.Value) ([]K, bool), val func(v ssa.Value) ([]V, bool)) ([][]MapEntry[K, V], bool) {
+	r := &resolver[[]MapEntry[K, V]]{
+		opts: opts,
+		flattener: func(v ssa.Value, next func(v ssa.Value) ([][]MapEntry[K, V], bool)) ([][]MapEntry[K, V], bool) {
+			switch t := v.(type) {
+			case *ssa.MakeMap:
+				return mapEntries(t, key, val)
+			case *ssa.ChangeType:
+				return next(t.X)
+			}
+			return [][]MapEntry[K, V]{}, false
+		},
+		mapper: func(t *ssa.Const) ([]MapEntry[K, V], bool) {
+			return []MapEntry[K, V]{}, t.IsNil()
+		},
+	}
+	return r.resolve(v, resolveState{})
+}
+
+// arrayElems resolves the elements of the array allocated by alloc from the stores to its elements.
+// It fails if the array may be written otherwise, such as by passing it to a call.
+func arrayElems[T any](alloc *ssa.Alloc, elem func(v ssa.Value) ([]T, bool)) ([][]T, bool) {
+	stores, ok := arrayStores(alloc)
+	if !ok {
+		return [][]T{}, false
+	}
+	lists := [][]T{{}}
+	for _, vals := range stores {
+		elems := make([]T, 0, len(vals))
+		for _, v := range vals {
+			es, ok := elem(v)
+			if !ok {
+				return [][]T{}, false
+			}
+			elems = append(elems, es...)
+		}
+		if lists, ok = crossElems(lists, elems); !ok {
+			return [][]T{}, false
+		}
+	}
+	return lists, true
+}
+
+// arrayStores returns the values stored to each element of the array allocated by alloc, and its zero value
+// unless a store to it always runs before the array is read. The elements of a global slice of the array may also be stored in any function that loads it.
+// It fails if the array may be written otherwise.
+func arrayStores(alloc *ssa.Alloc) ([][]ssa.Value, bool) {
+	ptr, ok := alloc.Type().Underlying().(*types.Pointer)
+	if !ok {
+		return nil, false
+	}
+	arr, ok := ptr.Elem().Underlying().(*types.Array)
+	if !ok {
+		return nil, false
+	}
+	w := &elemWrites{stores: make([][]ssa.Value, arr.Len()), seen: make(map[ssa.Value]bool)}
+	// always reports whether an element is stored before the array is read, so that it is not zero
+	always := make([]bool, arr.Len())
+	reads := arrayReads(alloc)
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.IndexAddr:
+			if !w.index(ref, 0, true) {
+				return nil, false
+			}
+			for _, r := range *ref.Referrers() {
+				if store, ok := r.(*ssa.Store); ok && dominatesAll(store, reads) {
+					always[ref.Index.(*ssa.Const).Int64()] = true
+				}
+			}
+		case *ssa.Store:
+			if c, ok := ref.Val.(*ssa.Const); ok && c.Value == nil {
+				continue // zeroing of a sparse literal
+			}
+			// e.g. *arr = *complit, copying an array literal built in another local array
+			load, ok := ref.Val.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL {
+				return nil, false
+			}
+			src, ok := load.X.(*ssa.Alloc)
+			if !ok || src == alloc {
+				return nil, false
+			}
+			copied, ok := arrayStores(src)
+			if !ok || len(copied) != len(w.stores) {
+				return nil, false
+			}
+			for i, vals := range copied {
+				w.stores[i] = append(w.stores[i], vals...)
+				always[i] = always[i] || dominatesAll(ref, reads)
+			}
+		case *ssa.Slice:
+			if !w.follow(ref, sliceOffset(ref, 0), ref.High == nil && ref.Max == nil, false) {
+				return nil, false
+			}
+		case *ssa.UnOp, *ssa.DebugRef:
+			// reads of the array
+		default:
+			return nil, false
+		}
+	}
+	for i := range w.stores {
+		if !always[i] {
+			w.stores[i] = append(w.stores[i], zeroConst(arr.Elem()))
+		}
+	}
+	return w.stores, true
+}
+
+// arrayReads returns the instructions that read the array allocated by alloc, as a whole or by element.
+func arrayReads(alloc *ssa.Alloc) []ssa.Instruction {
+	res := make([]ssa.Instruction, 0)
+	for _, ref := range *alloc.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Slice, *ssa.UnOp:
+			res = append(res, ref)
+		case *ssa.IndexAddr:
+			for _, r := range *ref.Referrers() {
+				if load, ok := r.(*ssa.UnOp); ok {
+					res = append(res, load)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// elemWrites collects the stores to the elements of an array through the slices of it.
+type elemWrites struct {
+	stores [][]ssa.Value
+	seen   map[ssa.Value]bool
+}
+
+// follow follows the uses of the slice v of the array, whose first element is the element offset of the array
+// or unknown if negative. full reports whether the length of v is its capacity, so that appending to it
+// allocates a new array. The stores to the elements are collected if global, and fail otherwise,
+// as they may come before the value is used.
+func (w *elemWrites) follow(v ssa.Value, offset int64, full, global bool) bool {
+	if w.seen[v] {
+		return true
+	}
+	w.seen[v] = true
+	for _, ref := range *v.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.IndexAddr:
+			if !w.index(ref, offset, global) {
+				return false
+			}
+		case *ssa.Slice:
+			if !w.follow(ref, sliceOffset(ref, offset), full && ref.High == nil && ref.Max == nil, global) {
+				return false
+			}
+		case *ssa.Phi:
+			if !w.follow(ref, offset, full, global) {
+				return false
+			}
+		case *ssa.ChangeType:
+			if !w.follow(ref, offset, full, global) {
+				return false
+			}
+		case *ssa.Store:
+			g, ok := ref.Addr.(*ssa.Global)
+			if !ok || ref.Val != v {
+				return false
+			}
+			loads, ok := globalLoads(g)
+			if !ok {
+				return false
+			}
+			for _, load := range loads {
+				if !w.follow(load, offset, full, true) {
+					return false
+				}
+			}
+		case *ssa.Return:
+			results, ok := callResults(ref.Parent())
+			if !ok {
+				return false
+			}
+			for _, res := range results {
+				if !w.follow(res, offset, full, global) {
+					return false
+				}
+			}
+		case ssa.CallInstruction:
+			if !w.call(ref.Common(), v, offset, full, global) {
+				return false
+			}
+		case *ssa.DebugRef, *ssa.BinOp:
+			// reads of the slice, or comparisons with nil
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// index collects the stores to the element of the slice at offset addressed by addr.
+// It fails if the element may be written otherwise, such as through a pointer passed to a call.
+func (w *elemWrites) index(addr *ssa.IndexAddr, offset int64, collect bool) bool {
+	for _, ref := range *addr.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			c, ok := addr.Index.(*ssa.Const)
+			if !ok || !collect || offset < 0 || ref.Addr != addr {
+				return false
+			}
+			i := offset + c.Int64()
+			if i < 0 || i >= int64(len(w.stores)) {
+				return false
+			}
+			w.stores[i] = append(w.stores[i], ref.Val)
+		case *ssa.UnOp, *ssa.DebugRef:
+			// reads of the element
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// call follows the slice v passed to the call, which may write to its elements.
+func (w *elemWrites) call(common *ssa.CallCommon, v ssa.Value, offset int64, full, global bool) bool {
+	if b, ok := common.Value.(*ssa.Builtin); ok {
+		switch {
+		case b.Name() == "len" || b.Name() == "cap":
+			return true
+		case b.Name() == "copy" || b.Name() == "append":
+			if common.Args[0] != v {
+				return true // the elements are read
+			}
+			if b.Name() == "copy" {
+				return false
+			}
+			// appending to a slice whose capacity is larger than its length writes to the array
+			return full
+		}
+		return false
+	}
+	if GetCallInfo(common).Match(readOnlyFuncs) {
+		return true
+	}
+	fn := common.StaticCallee()
+	if fn == nil || len(fn.Blocks) == 0 {
+		return false
+	}
+	for i, arg := range common.Args {
+		if arg == v && (i >= len(fn.Params) || !w.follow(fn.Params[i], offset, full, global)) {
+			return false
+		}
+	}
+	return true
+}
+
+// sliceOffset returns the offset of the slice t of a slice at offset, or -1 if unknown.
+func sliceOffset(t *ssa.Slice, offset int64) int64 {
+	if offset < 0 || t.Low == nil {
+		return offset
+	}
+	if c, ok := t.Low.(*ssa.Const); ok && c.Value != nil {
+		return offset + c.Int64()
+	}
+	return -1
+}
+
+// globalLoads returns the loads of g in the functions that may refer to it.
+// It fails if g is used otherwise than by a load or a store to it, such as by taking its address.
+func globalLoads(g *ssa.Global) ([]ssa.Value, bool) {
+	res := make([]ssa.Value, 0)
+	var buf [10]*ssa.Value
+	for _, fn := range scopeFunctions(g.Package(), g.Object() != nil && g.Object().Exported()) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if load, ok := instr.(*ssa.UnOp); ok && load.Op == token.MUL && load.X == g {
+					res = append(res, load)
+					continue
+				}
+				if store, ok := instr.(*ssa.Store); ok && store.Addr == g && store.Val != g {
+					continue
+				}
+				for _, op := range instr.Operands(buf[:0]) {
+					if *op == g {
+						return nil, false
+					}
+				}
+			}
+		}
+	}
+	return res, true
+}
+
+// callResults returns the results of the call sites of fn, which has a single result.
+func callResults(fn *ssa.Function) ([]ssa.Value, bool) {
+	if fn.Signature.Results().Len() != 1 {
+		return nil, false
+	}
+	res := make([]ssa.Value, 0)
+	for _, site := range callSites(fn) {
+		if v := site.Value(); v != nil {
+			res = append(res, v)
+		}
+	}
+	return res, true
+}
+
+// sliceElems slices each of lists with the constant bounds of t.
+func sliceElems[T any](t *ssa.Slice, lists [][]T, ints func(v ssa.Value) ([]int, bool)) ([][]T, bool) {
+	bound := func(v ssa.Value, def int) (int, bool) {
+		if v == nil {
+			return def, true
+		}
+		is, ok := ints(v)
+		if !ok || len(is) != 1 {
+			return 0, false
+		}
+		return is[0], true
+	}
+	res := make([][]T, 0, len(lists))
+	for _, list := range lists {
+		lo, lok := bound(t.Low, 0)
+		hi, hok := bound(t.High, len(list))
+		if !lok || !hok || lo < 0 || lo > hi || hi > len(list) {
+			return [][]T{}, false
+		}
+		res = append(res, list[lo:hi:hi])
+	}
+	return res, true
+}
+
+// mapEntries resolves the entries of the map made by mk from the updates of its entries,
+// including the updates through the values that it is merged into.
+// It fails if the map may be updated otherwise, such as in a call or through a global.
+func mapEntries[K comparable, V any](mk *ssa.MakeMap, key func(v ssa.Value) ([]K, bool), val func(v ssa.Value) ([]V, bool)) ([][]MapEntry[K, V], bool) {
+	w := &mapUpdates{reads: make(map[ssa.Value][]ssa.Instruction), seen: make(map[ssa.Value]bool)}
+	if !w.follow(mk, true) {
+		return [][]MapEntry[K, V]{}, false
+	}
+	lists := [][]MapEntry[K, V]{{}}
+	for _, update := range w.updates {
+		keys, ok := key(update.Key)
+		if !ok {
+			return [][]MapEntry[K, V]{}, false
+		}
+		vals, ok := val(update.Value)
+		if !ok {
+			return [][]MapEntry[K, V]{}, false
+		}
+		entries := make([]MapEntry[K, V], 0, len(keys)*len(vals))
+		for _, k := range keys {
+			for _, v := range vals {
+				entries = append(entries, MapEntry[K, V]{Key: k, Value: v})
+			}
+		}
+		if lists, ok = putEntries(lists, entries, !dominatesAll(update, w.reads[update.Map])); !ok {
+			return [][]MapEntry[K, V]{}, false
+		}
+	}
+	return lists, true
+}
+
+// putEntries puts each of entries to each of lists, keeping the lists without them too if optional.
+// It fails if there would be no lists or too many lists.
+func putEntries[K comparable, V any](lists [][]MapEntry[K, V], entries []MapEntry[K, V], optional bool) ([][]MapEntry[K, V], bool) {
+	n := len(lists) * len(entries)
+	if optional {
+		n += len(lists)
+	}
+	if len(entries) == 0 || n > maxElemLists {
+		return [][]MapEntry[K, V]{}, false
+	}
+	res := make([][]MapEntry[K, V], 0, n)
+	if optional {
+		res = append(res, lists...)
+	}
+	for _, list := range lists {
+		for _, e := range entries {
+			i := slices.IndexFunc(list, func(le MapEntry[K, V]) bool { return le.Key == e.Key })
+			if i < 0 {
+				res = append(res, append(list[:len(list):len(list)], e))
+				continue
+			}
+			put := slices.Clone(list)
+			put[i].Value = e.Value
+			res = append(res, put)
+		}
+	}
+	return res, true
+}
+
+// mapUpdates collects the updates of a map and the instructions that read each value of it
+// in the function making it.
+type mapUpdates struct {
+	updates []*ssa.MapUpdate
+	reads   map[ssa.Value][]ssa.Instruction
+	seen    map[ssa.Value]bool
+}
+
+// read records the use of v by ref other than an update.
+// An edge of a Phi is read at the end of its predecessor.
+func (w *mapUpdates) read(v ssa.Value, ref ssa.Instruction) {
+	switch ref := ref.(type) {
+	case *ssa.MapUpdate:
+	case *ssa.Phi:
+		for i, e := range ref.Edges {
+			if pred := ref.Block().Preds[i]; e == v {
+				w.reads[v] = append(w.reads[v], pred.Instrs[len(pred.Instrs)-1])
+			}
+		}
+	default:
+		w.reads[v] = append(w.reads[v], ref)
+	}
+}
+
+// follow follows the uses of the map v. The updates are collected if local, and fail otherwise,
+// as they may be made before the map is used.
+func (w *mapUpdates) follow(v ssa.Value, local bool) bool {
+	if w.seen[v] {
+		return true
+	}
+	w.seen[v] = true
+	for _, ref := range *v.Referrers() {
+		if local {
+			w.read(v, ref)
+		}
+		switch ref := ref.(type) {
+		case *ssa.MapUpdate:
+			if !local || ref.Map != v {
+				return false
+			}
+			w.updates = append(w.updates, ref)
+		case *ssa.Phi:
+			if !w.follow(ref, local) {
+				return false
+			}
+		case *ssa.ChangeType:
+			if !w.follow(ref, local) {
+				return false
+			}
+		case *ssa.Store:
+			g, ok := ref.Addr.(*ssa.Global)
+			if !ok || ref.Val != v {
+				return false
+			}
+			loads, ok := globalLoads(g)
+			if !ok {
+				return false
+			}
+			for _, load := range loads {
+				if !w.follow(load, false) {
+					return false
+				}
+			}
+		case *ssa.Return:
+			results, ok := callResults(ref.Parent())
+			if !ok {
+				return false
+			}
+			for _, res := range results {
+				if !w.follow(res, false) {
+					return false
+				}
+			}
+		case ssa.CallInstruction:
+			common := ref.Common()
+			if b, ok := common.Value.(*ssa.Builtin); ok {
+				if b.Name() != "len" {
+					return false // e.g. delete and clear
+				}
+				continue
+			}
+			fn := common.StaticCallee()
+			if fn == nil || len(fn.Blocks) == 0 {
+				return false
+			}
+			for i, arg := range common.Args {
+				if arg == v && (i >= len(fn.Params) || !w.follow(fn.Params[i], false)) {
+					return false
+				}
+			}
+		case *ssa.Lookup, *ssa.Range, *ssa.DebugRef, *ssa.BinOp:
+			// uses that do not update the map
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// dominatesAll reports whether instr runs before each of reads whenever it runs.
+func dominatesAll(instr ssa.Instruction, reads []ssa.Instruction) bool {
+	for _, read := range reads {
+		if _, ok := read.(*ssa.DebugRef); ok {
+			continue
+		}
+		if instr.Parent() != read.Parent() {
+			return false
+		}
+		if instr.Block() != read.Block() {
+			if !instr.Block().Dominates(read.Block()) {
+				return false
+			}
+			continue
+		}
+		for _, i := range instr.Block().Instrs {
+			if i == read {
+				return false
+			}
+			if i == instr {
+				break
+			}
+		}
+	}
+	return true
+}
+
+// crossElems appends each of elems to each of lists. It fails if there would be no lists or too many lists.
+func crossElems[T any](lists [][]T, elems []T) ([][]T, bool) {
+	if len(elems) == 0 || len(lists)*len(elems) > maxElemLists {
+		return [][]T{}, false
+	}
+	res := make([][]T, 0, len(lists)*len(elems))
+	for _, list := range lists {
+		for _, e := range elems {
+			res = append(res, append(list[:len(list):len(list)], e))
+		}
+	}
+	return res, true
+}
+
+// crossLists appends each of tails to each of lists. It fails if there would be no lists or too many lists.
+func crossLists[T any](lists, tails [][]T) ([][]T, bool) {
+	if len(tails) == 0 || len(lists)*len(tails) > maxElemLists {
+		return [][]T{}, false
+	}
+	res := make([][]T, 0, len(lists)*len(tails))
+	for _, list := range lists {
+		for _, tail := range tails {
+			res = append(res, append(list[:len(list):len(list)], tail...))
+		}
+	}
+	return res, true
+}
+
+// zeroConst returns the zero value of t.
+func zeroConst(t types.Type) *ssa.Const {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ssa.NewConst(nil, t)
+	}
+	switch info := b.Info(); {
+	case info&types.IsBoolean != 0:
+		return ssa.NewConst(constant.MakeBool(false), t)
+	case info&types.IsString != 0:
+		return ssa.NewConst(constant.MakeString(""), t)
+	case info&types.IsInteger != 0:
+		return ssa.NewConst(constant.MakeInt64(0), t)
+	case info&types.IsFloat != 0:
+		return ssa.NewConst(constant.MakeFloat64(0), t)
+	case info&types.IsComplex != 0:
+		return ssa.NewConst(constant.MakeImag(constant.MakeInt64(0)), t)
+	}
+	return ssa.NewConst(nil, t)
+}
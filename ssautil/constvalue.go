@@ -0,0 +1,495 @@
+package ssautil
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"math/big"
+	"slices"
+	"time"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ValueToConstValues returns the possible values of v as constants of any kind: booleans, strings, integers of any
+// size, floats and complex numbers. Arithmetic, comparisons, conversions and the built-ins complex, real and imag
+// are folded as at run time, so that results of integer types wrap around to the width of their type and results of
+// float32 are rounded.
+func ValueToConstValues(v ssa.Value) ([]constant.Value, bool) {
+	return ValueToConstValuesWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToConstValuesWithOptions(v ssa.Value, opts ResolveOptions) ([]constant.Value, bool) {
+	return ValueToConstsWithOptions[constant.Value](v, opts,
+		func(v ssa.Value, next func(v ssa.Value) ([]constant.Value, bool)) ([]constant.Value, bool) {
+			switch t := v.(type) {
+			case *ssa.BinOp:
+				x, xok := next(t.X)
+				y, yok := next(t.Y)
+				if !xok || !yok {
+					return []constant.Value{}, false
+				}
+				res := make([]constant.Value, 0, len(x)*len(y))
+				for _, xx := range x {
+					for _, yy := range y {
+						if r, ok := constBinOp(t.Op, xx, yy, t.X.Type(), t.Type()); ok {
+							res = append(res, r)
+						}
+					}
+				}
+				return res, len(res) > 0
+			case *ssa.UnOp:
+				if t.Op == token.MUL || t.Op == token.ARROW {
+					break // loads and receives
+				}
+				return mapConstValues(t.X, next, func(x constant.Value) (constant.Value, bool) {
+					return constUnOp(t.Op, x, t.Type())
+				})
+			case *ssa.Convert:
+				return mapConstValues(t.X, next, func(x constant.Value) (constant.Value, bool) {
+					return convertConst(x, t.Type())
+				})
+			case *ssa.ChangeType:
+				return mapConstValues(t.X, next, func(x constant.Value) (constant.Value, bool) {
+					return convertConst(x, t.Type())
+				})
+			case *ssa.Call:
+				if fn, ok := t.Call.Value.(*ssa.Builtin); ok {
+					switch fn.Name() {
+					case "complex":
+						re, reok := next(t.Call.Args[0])
+						im, imok := next(t.Call.Args[1])
+						if !reok || !imok {
+							return []constant.Value{}, false
+						}
+						res := make([]constant.Value, 0, len(re)*len(im))
+						for _, r := range re {
+							for _, i := range im {
+								if c, ok := makeComplex(r, i); ok {
+									res = append(res, c)
+								}
+							}
+						}
+						return res, len(res) > 0
+					case "real", "imag":
+						return mapConstValues(t.Call.Args[0], next, func(x constant.Value) (constant.Value, bool) {
+							if x = constant.ToComplex(x); x.Kind() != constant.Complex {
+								return nil, false
+							}
+							if fn.Name() == "real" {
+								return wrapConst(constant.Real(x), t.Type())
+							}
+							return wrapConst(constant.Imag(x), t.Type())
+						})
+					}
+				}
+				// other calls are resolved by the models of ValueToInts and ValueToStrings
+				b, ok := t.Type().Underlying().(*types.Basic)
+				switch {
+				case ok && b.Info()&types.IsInteger != 0:
+					ints, ok := ValueToIntsWithOptions(t, opts)
+					if !ok {
+						break
+					}
+					res := make([]constant.Value, 0, len(ints))
+					for _, i := range ints {
+						res = append(res, constant.MakeInt64(int64(i)))
+					}
+					return res, true
+				case ok && b.Info()&types.IsString != 0:
+					strs, ok := ValueToStringsWithOptions(t, opts)
+					if !ok {
+						break
+					}
+					res := make([]constant.Value, 0, len(strs))
+					for _, s := range strs {
+						res = append(res, constant.MakeString(s))
+					}
+					return res, true
+				}
+			}
+			return []constant.Value{}, false
+		},
+		func(t *ssa.Const) (constant.Value, bool) {
+			return t.Value, t.Value != nil
+		})
+}
+
+func mapConstValues(x ssa.Value, next func(v ssa.Value) ([]constant.Value, bool), fn func(x constant.Value) (constant.Value, bool)) ([]constant.Value, bool) {
+	xs, ok := next(x)
+	if !ok {
+		return []constant.Value{}, false
+	}
+	res := make([]constant.Value, 0, len(xs))
+	for _, xx := range xs {
+		if r, ok := fn(xx); ok {
+			res = append(res, r)
+		}
+	}
+	return res, len(res) > 0
+}
+
+// makeComplex returns the complex number re + im*i, as the built-in complex does.
+func makeComplex(re, im constant.Value) (constant.Value, bool) {
+	re, im = constant.ToFloat(re), constant.ToFloat(im)
+	if re.Kind() != constant.Float || im.Kind() != constant.Float {
+		return nil, false
+	}
+	return constant.BinaryOp(re, token.ADD, constant.MakeImag(im)), true
+}
+
+// constBinOp returns x op y, where the operands are of type xt and the result is of type t.
+func constBinOp(op token.Token, x, y constant.Value, xt, t types.Type) (constant.Value, bool) {
+	if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+		return nil, false
+	}
+	if !binaryOpDefined(op, x, y) {
+		return nil, false // mismatched kinds
+	}
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return constant.MakeBool(constant.Compare(x, op, y)), true
+	case token.SHL, token.SHR:
+		s, ok := constant.Uint64Val(constant.ToInt(y))
+		if !ok {
+			return nil, false // negative shift counts panic
+		}
+		if s > 1<<16 {
+			s = 1 << 16 // larger shifts of fixed size integers have the same results
+		}
+		return wrapConst(constant.Shift(x, op, uint(s)), t)
+	case token.QUO, token.REM:
+		if isIntegerType(xt) && constant.Sign(y) == 0 {
+			return nil, false // integer division by zero panics
+		}
+		if op == token.QUO && isIntegerType(xt) {
+			op = token.QUO_ASSIGN // integer division
+		}
+	}
+	return wrapConst(constant.BinaryOp(x, op, y), t)
+}
+
+// constUnOp returns op x of type t.
+func constUnOp(op token.Token, x constant.Value, t types.Type) (constant.Value, bool) {
+	if x.Kind() == constant.Unknown {
+		return nil, false
+	}
+	if !unaryOpDefined(op, x) {
+		return nil, false
+	}
+	var prec uint
+	if b, ok := t.Underlying().(*types.Basic); ok && b.Info()&types.IsUnsigned != 0 {
+		prec = uint(intSize(b.Kind()))
+	}
+	return wrapConst(constant.UnaryOp(op, x, prec), t)
+}
+
+// binaryOpDefined reports whether constant.BinaryOp, constant.Compare or constant.Shift is defined on x op y
+// instead of panicking. Numeric kinds are matched to the larger one.
+func binaryOpDefined(op token.Token, x, y constant.Value) bool {
+	xk, yk := x.Kind(), y.Kind()
+	if xk != yk && (!isNumericKind(xk) || !isNumericKind(yk)) {
+		return false
+	}
+	k := max(xk, yk) // Int < Float < Complex
+	switch op {
+	case token.EQL, token.NEQ:
+		return true
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return k == constant.String || k == constant.Int || k == constant.Float
+	case token.ADD:
+		return k != constant.Bool
+	case token.SUB, token.MUL, token.QUO:
+		return isNumericKind(k)
+	case token.SHL, token.SHR:
+		return xk == constant.Int
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		return k == constant.Int
+	}
+	return false
+}
+
+// unaryOpDefined reports whether constant.UnaryOp is defined on op x instead of panicking.
+func unaryOpDefined(op token.Token, x constant.Value) bool {
+	switch op {
+	case token.ADD, token.SUB:
+		return isNumericKind(x.Kind())
+	case token.XOR:
+		return x.Kind() == constant.Int
+	case token.NOT:
+		return x.Kind() == constant.Bool
+	}
+	return false
+}
+
+func isNumericKind(k constant.Kind) bool {
+	return k == constant.Int || k == constant.Float || k == constant.Complex
+}
+
+// convertConst converts x to the type t as at run time.
+func convertConst(x constant.Value, t types.Type) (constant.Value, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || x.Kind() == constant.Unknown {
+		return nil, false
+	}
+	switch {
+	case b.Info()&types.IsInteger != 0:
+		switch x.Kind() {
+		case constant.Int:
+			return wrapConst(x, t)
+		case constant.Float:
+			// conversions from floats truncate towards zero
+			f, _ := constant.Float64Val(x)
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, false
+			}
+			i, _ := big.NewFloat(f).Int(nil)
+			return wrapConst(constant.Make(i), t)
+		}
+	case b.Info()&types.IsFloat != 0:
+		if x.Kind() == constant.Int || x.Kind() == constant.Float {
+			return wrapConst(constant.ToFloat(x), t)
+		}
+	case b.Info()&types.IsComplex != 0:
+		if c := constant.ToComplex(x); c.Kind() == constant.Complex {
+			return c, true
+		}
+	case b.Info()&types.IsString != 0:
+		if x.Kind() == constant.String {
+			return x, true
+		}
+		if x.Kind() == constant.Int { // string(rune(i))
+			i, ok := constant.Int64Val(x)
+			if !ok {
+				return nil, false
+			}
+			return constant.MakeString(string(rune(i))), true
+		}
+	case b.Kind() == types.Bool:
+		if x.Kind() == constant.Bool {
+			return x, true
+		}
+	}
+	return nil, false
+}
+
+// wrapConst returns the value of x in the type t, wrapping integers around to the width of t
+// and rounding floats to the precision of t.
+func wrapConst(x constant.Value, t types.Type) (constant.Value, bool) {
+	if x.Kind() == constant.Unknown {
+		return nil, false
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return x, true
+	}
+	switch {
+	case b.Info()&types.IsInteger != 0 && x.Kind() == constant.Int:
+		size := intSize(b.Kind())
+		if size == 0 {
+			return x, true // untyped
+		}
+		mod := new(big.Int).Lsh(big.NewInt(1), uint(size))
+		i := new(big.Int).Mod(constBigInt(x), mod)
+		if b.Info()&types.IsUnsigned == 0 && i.Cmp(new(big.Int).Rsh(mod, 1)) >= 0 {
+			i.Sub(i, mod)
+		}
+		return constant.Make(i), true
+	case b.Kind() == types.Float32:
+		f, _ := constant.Float32Val(x)
+		return constant.MakeFloat64(float64(f)), true
+	case b.Kind() == types.Float64:
+		f, _ := constant.Float64Val(x)
+		return constant.MakeFloat64(f), true
+	}
+	return x, true
+}
+
+// intSize returns the size in bits of the integer kind k, or 0 for untyped integers. int, uint and uintptr are 64 bits.
+func intSize(k types.BasicKind) int {
+	switch k {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	case types.Int, types.Int64, types.Uint, types.Uint64, types.Uintptr:
+		return 64
+	}
+	return 0
+}
+
+func isIntegerType(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+func constBigInt(x constant.Value) *big.Int {
+	switch v := constant.Val(x).(type) {
+	case int64:
+		return big.NewInt(v)
+	case *big.Int:
+		return new(big.Int).Set(v)
+	}
+	return nil
+}
+
+// ValueToFloats returns the possible values of the numeric value v as float64.
+func ValueToFloats(v ssa.Value) ([]float64, bool) {
+	return ValueToFloatsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToFloatsWithOptions(v ssa.Value, opts ResolveOptions) ([]float64, bool) {
+	return mapConsts(v, opts, func(c constant.Value) (float64, bool) {
+		if c.Kind() != constant.Int && c.Kind() != constant.Float {
+			return 0, false
+		}
+		f, _ := constant.Float64Val(constant.ToFloat(c))
+		return f, true
+	})
+}
+
+// ValueToBools returns the possible values of the boolean value v.
+func ValueToBools(v ssa.Value) ([]bool, bool) {
+	return ValueToBoolsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToBoolsWithOptions(v ssa.Value, opts ResolveOptions) ([]bool, bool) {
+	return mapConsts(v, opts, func(c constant.Value) (bool, bool) {
+		if c.Kind() != constant.Bool {
+			return false, false
+		}
+		return constant.BoolVal(c), true
+	})
+}
+
+// ValueToComplexes returns the possible values of the numeric value v as complex128.
+func ValueToComplexes(v ssa.Value) ([]complex128, bool) {
+	return ValueToComplexesWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToComplexesWithOptions(v ssa.Value, opts ResolveOptions) ([]complex128, bool) {
+	return mapConsts(v, opts, func(c constant.Value) (complex128, bool) {
+		c = constant.ToComplex(c)
+		if c.Kind() != constant.Complex {
+			return 0, false
+		}
+		re, _ := constant.Float64Val(constant.Real(c))
+		im, _ := constant.Float64Val(constant.Imag(c))
+		return complex(re, im), true
+	})
+}
+
+// ValueToBigInts returns the possible values of the integer value v, including values beyond the range of int
+// such as large uint64 values.
+func ValueToBigInts(v ssa.Value) ([]*big.Int, bool) {
+	return ValueToBigIntsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToBigIntsWithOptions(v ssa.Value, opts ResolveOptions) ([]*big.Int, bool) {
+	return mapConsts(v, opts, func(c constant.Value) (*big.Int, bool) {
+		if c = constant.ToInt(c); c.Kind() != constant.Int {
+			return nil, false
+		}
+		return constBigInt(c), true
+	})
+}
+
+// ValueToDurations returns the possible values of the time.Duration value v, such as 5 * time.Second.
+// It is false if v is not of type time.Duration.
+func ValueToDurations(v ssa.Value) ([]time.Duration, bool) {
+	return ValueToDurationsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToDurationsWithOptions(v ssa.Value, opts ResolveOptions) ([]time.Duration, bool) {
+	if !isDuration(v.Type()) {
+		return []time.Duration{}, false
+	}
+	return mapConsts(v, opts, func(c constant.Value) (time.Duration, bool) {
+		if c = constant.ToInt(c); c.Kind() != constant.Int {
+			return 0, false
+		}
+		i, exact := constant.Int64Val(c)
+		return time.Duration(i), exact
+	})
+}
+
+func isDuration(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Duration"
+}
+
+// NamedConst is a possible value of a value of a named type, such as an enum-style constant declared with iota.
+type NamedConst struct {
+	Value constant.Value
+	// Const is the constant of the named type declared with the value in the package of the type,
+	// or nil if there is none.
+	Const *types.Const
+}
+
+// Name returns the qualified name of the constant such as "time.Second", or the value if there is no constant.
+func (n NamedConst) Name() string {
+	if n.Const == nil {
+		return n.Value.ExactString()
+	}
+	if n.Const.Pkg() == nil {
+		return n.Const.Name()
+	}
+	return n.Const.Pkg().Name() + "." + n.Const.Name()
+}
+
+// ValueToNamedConsts returns the possible values of v with the constants of the type of v that are declared with them.
+// If several constants have the same value, the one declared first is used.
+func ValueToNamedConsts(v ssa.Value) ([]NamedConst, bool) {
+	return ValueToNamedConstsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToNamedConstsWithOptions(v ssa.Value, opts ResolveOptions) ([]NamedConst, bool) {
+	vals, ok := ValueToConstValuesWithOptions(v, opts)
+	if !ok {
+		return []NamedConst{}, false
+	}
+	consts := declaredConsts(v.Type())
+	res := make([]NamedConst, 0, len(vals))
+	for _, val := range vals {
+		nc := NamedConst{Value: val}
+		if i := slices.IndexFunc(consts, func(c *types.Const) bool { return constant.Compare(c.Val(), token.EQL, val) }); i >= 0 {
+			nc.Const = consts[i]
+		}
+		res = append(res, nc)
+	}
+	return res, true
+}
+
+// declaredConsts returns the constants of the named type t declared in the package of t, in the order of declaration.
+func declaredConsts(t types.Type) []*types.Const {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return nil
+	}
+	scope := named.Obj().Pkg().Scope()
+	res := make([]*types.Const, 0)
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), t) {
+			res = append(res, c)
+		}
+	}
+	slices.SortStableFunc(res, func(a, b *types.Const) int { return int(a.Pos() - b.Pos()) })
+	return res
+}
+
+func mapConsts[T any](v ssa.Value, opts ResolveOptions, fn func(c constant.Value) (T, bool)) ([]T, bool) {
+	vals, ok := ValueToConstValuesWithOptions(v, opts)
+	if !ok {
+		return []T{}, false
+	}
+	res := make([]T, 0, len(vals))
+	for _, val := range vals {
+		if r, ok := fn(val); ok {
+			res = append(res, r)
+		}
+	}
+	return res, len(res) > 0
+}
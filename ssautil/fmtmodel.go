@@ -0,0 +1,415 @@
+package ssautil
+
+import (
+	"fmt"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxFmtResults is the maximum number of possible results of a formatting call.
+const maxFmtResults = 64
+
+// fmtModel models the formatting functions of the fmt package on abstract strings.
+type fmtModel struct {
+	ctx *ModelContext
+}
+
+// fmtStringModel is the StringModel of the formatting functions of the fmt package.
+func fmtStringModel(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	m := &fmtModel{ctx: ctx}
+	return m.call(c)
+}
+
+// call returns the possible results of c if c is a call of fmt.Sprintf, fmt.Sprint, fmt.Sprintln, fmt.Errorf,
+// fmt.Appendf, fmt.Append or fmt.Appendln. The result of fmt.Errorf is its message.
+func (m *fmtModel) call(c CallInfo) ([]AbstractString, bool) {
+	var fixed int // the number of parameters before the variadic ...any parameter
+	switch {
+	case c.Match("fmt.Sprint|fmt.Sprintln"):
+		fixed = 0
+	case c.Match("fmt.Sprintf|fmt.Errorf|fmt.Append|fmt.Appendln"):
+		fixed = 1
+	case c.Match("fmt.Appendf"):
+		fixed = 2
+	default:
+		return nil, false
+	}
+	args := c.Args()
+	if n := len(args); n < fixed || (n > fixed && isSpread(args[n-1])) {
+		return nil, false // e.g. fmt.Sprintf(format, args...)
+	}
+	switch {
+	case c.Match("fmt.Sprintf|fmt.Errorf"):
+		return m.formatted(nil, args[0], args[1:])
+	case c.Match("fmt.Sprint|fmt.Sprintln"):
+		return m.sprint(args, c.Match("fmt.Sprintln"))
+	case c.Match("fmt.Appendf"):
+		return m.formatted(args[0], args[1], args[2:])
+	default: // fmt.Append and fmt.Appendln
+		res, ok := m.sprint(args[1:], c.Match("fmt.Appendln"))
+		return m.prepend(args[0], res, ok)
+	}
+}
+
+// formatted returns the results of formatting args with the possible formats of format, appended to buf if not nil.
+func (m *fmtModel) formatted(buf, format ssa.Value, args []ssa.Value) ([]AbstractString, bool) {
+	formats, ok := m.ctx.Strings(format)
+	if !ok {
+		return nil, false
+	}
+	res := make([]AbstractString, 0)
+	for _, f := range formats {
+		if !f.IsConst() {
+			return nil, false
+		}
+		rs, ok := m.sprintf(f.String(), args)
+		if !ok {
+			return nil, false
+		}
+		res = append(res, rs...)
+	}
+	if buf == nil {
+		return res, true
+	}
+	return m.prepend(buf, res, true)
+}
+
+// prepend prepends the possible contents of the byte slice buf to res.
+func (m *fmtModel) prepend(buf ssa.Value, res []AbstractString, ok bool) ([]AbstractString, bool) {
+	if !ok {
+		return nil, false
+	}
+	if c, isConst := buf.(*ssa.Const); isConst && c.IsNil() {
+		return res, true
+	}
+	prefixes, ok := m.ctx.Strings(buf)
+	if !ok {
+		return nil, false
+	}
+	return productOf(prefixes, res)
+}
+
+// sprintf formats args with format like fmt.Sprintf.
+func (m *fmtModel) sprintf(format string, args []ssa.Value) ([]AbstractString, bool) {
+	res := []AbstractString{{}}
+	argNum := 0
+	reordered := false // extra arguments are only reported without explicit argument indexes
+	for i := 0; i < len(format); {
+		j := strings.IndexByte(format[i:], '%')
+		if j < 0 {
+			return productOf(res, []AbstractString{LiteralString(format[i:])})
+		}
+		res, _ = productOf(res, []AbstractString{LiteralString(format[i : i+j])})
+		i += j + 1
+
+		// %[flags][[n]][width][.[[n]]precision][[n]]verb
+		spec := []byte{'%'}
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			spec = append(spec, format[i])
+			i++
+		}
+		known := true
+		argIndex := func() bool {
+			if i >= len(format) || format[i] != '[' {
+				return true
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return false
+			}
+			n, err := strconv.Atoi(format[i+1 : i+end])
+			if err != nil || n < 1 || n > len(args) {
+				return false
+			}
+			argNum, i, reordered = n-1, i+end+1, true
+			return true
+		}
+		number := func() bool {
+			if i < len(format) && format[i] == '*' {
+				i++
+				if argNum >= len(args) {
+					return false
+				}
+				ns, ok := m.intArg(args[argNum])
+				argNum++
+				if ok && len(ns) == 1 {
+					spec = strconv.AppendInt(spec, int64(ns[0]), 10)
+				} else {
+					known = false
+				}
+				return true
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				spec = append(spec, format[i])
+				i++
+			}
+			return true
+		}
+		if !argIndex() || !number() {
+			return nil, false
+		}
+		if i < len(format) && format[i] == '.' {
+			spec = append(spec, '.')
+			i++
+			if !argIndex() || !number() {
+				return nil, false
+			}
+		}
+		if !argIndex() || i >= len(format) {
+			return nil, false
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+
+		var operand []AbstractString
+		switch {
+		case verb == '%':
+			operand = []AbstractString{LiteralString("%")}
+		case argNum >= len(args):
+			operand = []AbstractString{LiteralString("%!" + string(verb) + "(MISSING)")}
+		default:
+			var ok bool
+			operand, ok = m.operand(string(append(spec, string(verb)...)), verb, args[argNum])
+			argNum++
+			if !ok || !known {
+				h, ok := m.ctx.Hole(verb)
+				if !ok {
+					return nil, false
+				}
+				operand = []AbstractString{h}
+			}
+		}
+		var ok bool
+		if res, ok = productOf(res, operand); !ok {
+			return nil, false
+		}
+	}
+	if !reordered && argNum < len(args) {
+		return nil, false // extra arguments are reported by fmt as %!(EXTRA ...)
+	}
+	return res, true
+}
+
+// sprint formats args like fmt.Sprint, or like fmt.Sprintln if ln is true.
+func (m *fmtModel) sprint(args []ssa.Value, ln bool) ([]AbstractString, bool) {
+	res := []AbstractString{{}}
+	for i, arg := range args {
+		if i > 0 {
+			prevStr, prevOK := isStringOperand(args[i-1])
+			str, ok := isStringOperand(arg)
+			switch {
+			case ln || (prevOK && ok && !prevStr && !str):
+				res, _ = productOf(res, []AbstractString{LiteralString(" ")})
+			case !prevOK || !ok:
+				h, ok := m.ctx.Hole('v') // whether a space is added is unknown
+				if !ok {
+					return nil, false
+				}
+				res, _ = productOf(res, []AbstractString{h})
+			}
+		}
+		operand, ok := m.operand("%v", 'v', arg)
+		if !ok {
+			h, ok := m.ctx.Hole('v')
+			if !ok {
+				return nil, false
+			}
+			operand = []AbstractString{h}
+		}
+		if res, ok = productOf(res, operand); !ok {
+			return nil, false
+		}
+	}
+	if ln {
+		res, _ = productOf(res, []AbstractString{LiteralString("\n")})
+	}
+	return res, true
+}
+
+// operand returns the possible results of formatting arg with the directive spec whose verb is verb.
+func (m *fmtModel) operand(spec string, verb rune, arg ssa.Value) ([]AbstractString, bool) {
+	mi, ok := arg.(*ssa.MakeInterface)
+	if !ok {
+		return nil, false // the dynamic type of an interface value is unknown
+	}
+	x, t := mi.X, mi.X.Type()
+	if verb == 'T' {
+		return []AbstractString{LiteralString(types.TypeString(t, func(p *types.Package) string { return p.Name() }))}, true
+	}
+	if hasFormatMethod(t) {
+		return nil, false
+	}
+	if verb == 'w' {
+		spec = spec[:len(spec)-1] + "v"
+	}
+
+	if isString(t) {
+		strs, ok := m.ctx.Strings(x)
+		if !ok {
+			return nil, false
+		}
+		if spec == "%s" || spec == "%v" {
+			return strs, true
+		}
+		res := make([]AbstractString, 0, len(strs))
+		for _, s := range strs {
+			if !s.IsConst() {
+				return nil, false
+			}
+			res = append(res, LiteralString(fmt.Sprintf(spec, s.String())))
+		}
+		return res, true
+	}
+
+	vals, ok := m.goValues(x)
+	if !ok {
+		return nil, false
+	}
+	res := make([]AbstractString, 0, len(vals))
+	for _, v := range vals {
+		res = append(res, LiteralString(fmt.Sprintf(spec, v)))
+	}
+	return res, true
+}
+
+// goValues returns the possible values of x as Go values of the basic kind of its type.
+func (m *fmtModel) goValues(x ssa.Value) ([]any, bool) {
+	if c, ok := x.(*ssa.Const); ok {
+		if c.Value == nil {
+			return nil, false
+		}
+		v, ok := constGoValue(c.Value, c.Type())
+		return []any{v}, ok
+	}
+	if b, ok := x.Type().Underlying().(*types.Basic); ok && b.Info()&types.IsInteger != 0 {
+		ints, ok := m.intArg(x)
+		if !ok {
+			return nil, false
+		}
+		res := make([]any, 0, len(ints))
+		for _, i := range ints {
+			v, ok := constGoValue(constant.MakeInt64(int64(i)), x.Type())
+			if !ok {
+				return nil, false
+			}
+			res = append(res, v)
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+func (m *fmtModel) intArg(v ssa.Value) ([]int, bool) {
+	if mi, ok := v.(*ssa.MakeInterface); ok {
+		v = mi.X
+	}
+	return m.ctx.Ints(v)
+}
+
+// constGoValue converts the constant v to a Go value of the basic kind of t.
+func constGoValue(v constant.Value, t types.Type) (any, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+	i64, _ := constant.Int64Val(constant.ToInt(v))
+	u64, _ := constant.Uint64Val(constant.ToInt(v))
+	f64, _ := constant.Float64Val(constant.ToFloat(v))
+	switch b.Kind() {
+	case types.Bool:
+		return constant.BoolVal(v), v.Kind() == constant.Bool
+	case types.String:
+		return constant.StringVal(v), v.Kind() == constant.String
+	case types.Int:
+		return int(i64), true
+	case types.Int8:
+		return int8(i64), true
+	case types.Int16:
+		return int16(i64), true
+	case types.Int32:
+		return int32(i64), true
+	case types.Int64:
+		return i64, true
+	case types.Uint:
+		return uint(u64), true
+	case types.Uint8:
+		return uint8(u64), true
+	case types.Uint16:
+		return uint16(u64), true
+	case types.Uint32:
+		return uint32(u64), true
+	case types.Uint64:
+		return u64, true
+	case types.Uintptr:
+		return uintptr(u64), true
+	case types.Float32:
+		return float32(f64), true
+	case types.Float64:
+		return f64, true
+	case types.Complex64, types.Complex128:
+		re, _ := constant.Float64Val(constant.Real(v))
+		im, _ := constant.Float64Val(constant.Imag(v))
+		if b.Kind() == types.Complex64 {
+			return complex64(complex(re, im)), true
+		}
+		return complex(re, im), true
+	}
+	return nil, false
+}
+
+// hasFormatMethod reports whether values of t may be formatted by their own methods.
+func hasFormatMethod(t types.Type) bool {
+	mset := types.NewMethodSet(t)
+	for _, name := range []string{"Format", "GoString", "Error", "String"} {
+		if mset.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isStringOperand reports whether the operand arg of fmt.Sprint is a string. It is false if the dynamic type is unknown.
+func isStringOperand(arg ssa.Value) (bool, bool) {
+	if mi, ok := arg.(*ssa.MakeInterface); ok {
+		b, ok := mi.X.Type().Underlying().(*types.Basic)
+		return ok && b.Kind() == types.String, true
+	}
+	return false, false
+}
+
+// isSpread reports whether the last argument of a variadic ...any parameter is a slice passed with "...".
+func isSpread(arg ssa.Value) bool {
+	_, ok := arg.Type().Underlying().(*types.Slice)
+	return ok
+}
+
+func productOf(xs, ys []AbstractString) ([]AbstractString, bool) {
+	res := concatAll(xs, ys)
+	return res, len(res) <= maxFmtResults
+}
+
+// fmtPlaceholders are the sample results of the verbs of ValueToStrings, whose unknown operands are substituted.
+var fmtPlaceholders = map[rune]string{
+	'b': "01",
+	'c': "a",
+	't': "true",
+	'T': "string",
+	'e': "1.234000e+08",
+	'E': "1.234000E+08",
+	'p': "0xc0000ba000",
+	'x': "1f",
+	'd': "1",
+	'f': "1.0",
+}
+
+// fmtPlaceholder returns the sample result of a verb from fmtPlaceholders.
+// Verbs without a placeholder, such as %s and %v, make the result unknown.
+func fmtPlaceholder(verb rune) (AbstractString, bool) {
+	placeholder, ok := fmtPlaceholders[verb]
+	return LiteralString(placeholder), ok
+}
@@ -0,0 +1,47 @@
+package ssautil
+
+import (
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ValueToFuncs returns the possible concrete functions that the function value v refers to.
+// It follows variables and struct fields to their stores and closures to their functions, and as configured by the
+// options, parameters to the arguments of their call sites and results of calls to the returns of their static callees.
+func ValueToFuncs(v ssa.Value) ([]*ssa.Function, bool) {
+	return ValueToFuncsWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToFuncsWithMaxDepth(v ssa.Value, maxDepth int) ([]*ssa.Function, bool) {
+	return ValueToFuncsWithOptions(v, withMaxDepth(maxDepth))
+}
+
+func ValueToFuncsWithOptions(v ssa.Value, opts ResolveOptions) ([]*ssa.Function, bool) {
+	fns, ok := ValueToConstsWithOptions[*ssa.Function](v, opts,
+		func(v ssa.Value, next func(v ssa.Value) ([]*ssa.Function, bool)) ([]*ssa.Function, bool) {
+			switch t := v.(type) {
+			case *ssa.Function:
+				return []*ssa.Function{t}, true
+			case *ssa.MakeClosure:
+				return next(t.Fn)
+			case *ssa.ChangeType:
+				return next(t.X)
+			}
+			return []*ssa.Function{}, false
+		},
+		func(t *ssa.Const) (*ssa.Function, bool) {
+			return nil, false // nil function
+		})
+	if !ok {
+		return fns, false
+	}
+
+	res := make([]*ssa.Function, 0, len(fns))
+	for _, fn := range fns {
+		if !slices.Contains(res, fn) {
+			res = append(res, fn)
+		}
+	}
+	return res, true
+}
@@ -0,0 +1,149 @@
+package ssautil
+
+import (
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxModelRuns is the maximum number of combinations of argument values that a modeled call is resolved with
+// in ValueToStringsGuarded.
+const maxModelRuns = 64
+
+// Condition is the outcome of a branch.
+type Condition struct {
+	If *ssa.If
+	// Value is the value of the condition of If in the branch taken.
+	Value bool
+}
+
+// Guarded is a resolved value with the conditions of the branches that it flows through.
+type Guarded[T any] struct {
+	Value T
+	// Conds are the conditions of the branches from the immediate dominator of each Phi that the value flows through
+	// to the edge of the value, outermost first. The value is the resolved value if all of them hold.
+	// Values merged otherwise, such as the values stored to a variable, have no conditions.
+	Conds []Condition
+}
+
+// Resolution is the result of a path-sensitive resolution.
+type Resolution[T any] struct {
+	Values []Guarded[T]
+	// Complete reports whether no value was dropped because it was not resolved.
+	// If true, the value is always one of Values, otherwise it is sometimes one of Values.
+	Complete bool
+}
+
+// ValueToStringsGuarded is like ValueToStrings but also returns the branch conditions under which v is each string,
+// and whether v is always one of them.
+func ValueToStringsGuarded(v ssa.Value) (Resolution[string], bool) {
+	return ValueToStringsGuardedWithOptions(v, DefaultResolveOptions())
+}
+
+// ValueToStringsGuardedWithOptions is like ValueToStringsGuarded with opts.
+// If opts.Strict, it fails instead of returning an incomplete resolution.
+func ValueToStringsGuardedWithOptions(v ssa.Value, opts ResolveOptions) (Resolution[string], bool) {
+	r := &resolver[Guarded[string]]{
+		opts: opts,
+		mapper: func(t *ssa.Const) (Guarded[string], bool) {
+			s, ok := constToString(t)
+			return Guarded[string]{Value: s}, ok
+		},
+		guard: func(phi *ssa.Phi, edge int, res []Guarded[string]) []Guarded[string] {
+			conds := edgeConds(phi, edge)
+			guarded := make([]Guarded[string], 0, len(res))
+			for _, g := range res {
+				guarded = append(guarded, Guarded[string]{Value: g.Value, Conds: concatConds(conds, g.Conds)})
+			}
+			return guarded
+		},
+	}
+	f := &stringFlattener[Guarded[string]]{
+		opts: opts,
+		str:  func(g Guarded[string]) string { return g.Value },
+		derive: func(s string, from ...Guarded[string]) Guarded[string] {
+			var conds []Condition
+			for _, g := range from {
+				conds = concatConds(conds, g.Conds)
+			}
+			return Guarded[string]{Value: s, Conds: conds}
+		},
+		// an unknown operand of a modeled call is a sample value, with which the resolution is not complete
+		hole: func(verb rune) (AbstractString, bool) {
+			if opts.Strict {
+				return nil, false
+			}
+			h, ok := fmtPlaceholder(verb)
+			r.dropped = r.dropped || ok
+			return h, ok
+		},
+		ints: func(v ssa.Value) ([]int, bool) {
+			ir := newIntResolver(crossOptions(opts))
+			res, ok := ir.resolve(v, resolveState{})
+			r.dropped = r.dropped || ir.dropped
+			return res, ok
+		},
+		enumerate: true,
+	}
+	r.flattener = f.flatten
+	res, ok := r.resolve(v, resolveState{})
+	if !ok {
+		return Resolution[string]{}, false
+	}
+	return Resolution[string]{Values: res, Complete: !r.dropped}, true
+}
+
+// concatConds returns the conditions of x and y, without duplicates.
+func concatConds(x, y []Condition) []Condition {
+	res := slices.Clip(x)
+	for _, c := range y {
+		if !slices.Contains(res, c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// edgeConds returns the conditions of the branches taken from the immediate dominator of the block of phi
+// to the predecessor of the edge, outermost first.
+func edgeConds(phi *ssa.Phi, edge int) []Condition {
+	b := phi.Block()
+	if edge >= len(b.Preds) {
+		return nil
+	}
+	idom := b.Idom()
+	conds := make([]Condition, 0)
+	for to, from := b, b.Preds[edge]; from != nil; to, from = from, from.Idom() {
+		if c, ok := branchCond(from, to); ok {
+			conds = append(conds, c)
+		}
+		if from == idom {
+			break
+		}
+	}
+	slices.Reverse(conds)
+	return conds
+}
+
+// branchCond returns the condition under which the control flows from the block from to the block to,
+// which is a successor of from or a block dominated by from.
+// It returns false if from does not end with an If or if both of its branches may flow to the block.
+func branchCond(from, to *ssa.BasicBlock) (Condition, bool) {
+	if len(from.Instrs) == 0 {
+		return Condition{}, false
+	}
+	ifInstr, ok := from.Instrs[len(from.Instrs)-1].(*ssa.If)
+	if !ok {
+		return Condition{}, false
+	}
+	then, els := from.Succs[0], from.Succs[1]
+	switch {
+	case then == els:
+		return Condition{}, false
+	case then.Dominates(to) && !els.Dominates(to):
+		return Condition{If: ifInstr, Value: true}, true
+	case els.Dominates(to) && !then.Dominates(to):
+		return Condition{If: ifInstr, Value: false}, true
+	}
+	return Condition{}, false
+}
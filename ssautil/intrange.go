@@ -0,0 +1,593 @@
+package ssautil
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"math"
+	"math/bits"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// IntRange is the closed interval [Min, Max] of the possible values of an integer value.
+// Values of 64-bit unsigned types above math.MaxInt64 are not represented.
+type IntRange struct {
+	Min int64
+	Max int64
+}
+
+// PointRange returns the IntRange of the constant i.
+func PointRange(i int64) IntRange {
+	return IntRange{Min: i, Max: i}
+}
+
+// Contains reports whether i is in r.
+func (r IntRange) Contains(i int64) bool {
+	return r.Min <= i && i <= r.Max
+}
+
+// IsConst reports whether r has a single value.
+func (r IntRange) IsConst() bool {
+	return r.Min == r.Max
+}
+
+// Union returns the smallest range that contains both r and o.
+func (r IntRange) Union(o IntRange) IntRange {
+	return IntRange{Min: min(r.Min, o.Min), Max: max(r.Max, o.Max)}
+}
+
+func (r IntRange) String() string {
+	return fmt.Sprintf("[%d,%d]", r.Min, r.Max)
+}
+
+// TypeRange returns the range of the values of the integer type t.
+// It is false if t is not an integer type or its values are not representable, as for uint64.
+func TypeRange(t types.Type) (IntRange, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return IntRange{}, false
+	}
+	switch b.Kind() {
+	case types.Int8:
+		return IntRange{Min: math.MinInt8, Max: math.MaxInt8}, true
+	case types.Int16:
+		return IntRange{Min: math.MinInt16, Max: math.MaxInt16}, true
+	case types.Int32:
+		return IntRange{Min: math.MinInt32, Max: math.MaxInt32}, true
+	case types.Int, types.Int64, types.UntypedInt, types.UntypedRune:
+		return IntRange{Min: math.MinInt64, Max: math.MaxInt64}, true
+	case types.Uint8:
+		return IntRange{Min: 0, Max: math.MaxUint8}, true
+	case types.Uint16:
+		return IntRange{Min: 0, Max: math.MaxUint16}, true
+	case types.Uint32:
+		return IntRange{Min: 0, Max: math.MaxUint32}, true
+	}
+	return IntRange{}, false
+}
+
+// ValueToIntRange returns the range of the possible values of the integer value v.
+// Unlike ValueToInts, it also bounds values whose exact set is unknown, such as the results of bitwise operations
+// on unknown operands and induction variables of loops. Overflowing operations yield the range of their type.
+// It is false if nothing is known about v beyond its type, in which case the range of the type is returned if any.
+func ValueToIntRange(v ssa.Value) (IntRange, bool) {
+	return ValueToIntRangeWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToIntRangeWithOptions(v ssa.Value, opts ResolveOptions) (IntRange, bool) {
+	rs, ok := ValueToConstsWithOptions[IntRange](v, opts,
+		func(v ssa.Value, next func(v ssa.Value) ([]IntRange, bool)) ([]IntRange, bool) {
+			if _, ok := TypeRange(v.Type()); !ok {
+				return []IntRange{}, false
+			}
+			rangeOf := func(v ssa.Value) (IntRange, bool) {
+				if rs, ok := next(v); ok && len(rs) > 0 {
+					return unionAll(rs), true
+				}
+				return TypeRange(v.Type())
+			}
+			// shiftCountOf is rangeOf for a shift count, which is non-negative if it is of an unsigned type
+			// that is not representable such as uint64.
+			shiftCountOf := func(v ssa.Value) (IntRange, bool) {
+				if r, ok := rangeOf(v); ok {
+					return r, true
+				}
+				return IntRange{Min: 0, Max: math.MaxInt64}, isUnsigned(v.Type())
+			}
+			switch t := v.(type) {
+			case *ssa.Phi:
+				return loopPhiRange(t, rangeOf)
+			case *ssa.Call:
+				ints, ok := ValueToIntsWithOptions(t, opts)
+				if !ok || len(ints) == 0 {
+					return []IntRange{}, false
+				}
+				res := make([]IntRange, 0, len(ints))
+				for _, i := range ints {
+					res = append(res, PointRange(int64(i)))
+				}
+				return res, true
+			case *ssa.BinOp:
+				x, xok := rangeOf(t.X)
+				y, yok := rangeOf(t.Y)
+				if t.Op == token.SHL || t.Op == token.SHR {
+					y, yok = shiftCountOf(t.Y)
+				}
+				if !xok || !yok {
+					return []IntRange{}, false
+				}
+				if r, ok := binOpRange(t.Op, x, y, t.Type()); ok {
+					return []IntRange{r}, true
+				}
+			case *ssa.UnOp:
+				if t.Op != token.SUB && t.Op != token.XOR {
+					break
+				}
+				if x, ok := rangeOf(t.X); ok {
+					r, _ := unOpRange(t.Op, x, t.Type())
+					return []IntRange{r}, true
+				}
+			case *ssa.Convert:
+				if r, ok := rangeOf(t.X); ok {
+					return []IntRange{fitRange(r, t.Type())}, true
+				}
+			case *ssa.ChangeType:
+				if r, ok := rangeOf(t.X); ok {
+					return []IntRange{fitRange(r, t.Type())}, true
+				}
+			}
+			return []IntRange{}, false
+		},
+		func(t *ssa.Const) (IntRange, bool) {
+			if t.Value == nil || t.Value.Kind() != constant.Int {
+				return IntRange{}, false
+			}
+			i, exact := constant.Int64Val(t.Value)
+			return PointRange(i), exact
+		})
+	full, _ := TypeRange(v.Type())
+	if !ok || len(rs) == 0 {
+		return full, false
+	}
+	r := unionAll(rs)
+	return r, r != full
+}
+
+func unionAll(rs []IntRange) IntRange {
+	res := rs[0]
+	for _, r := range rs[1:] {
+		res = res.Union(r)
+	}
+	return res
+}
+
+// fitRange returns r converted to the integer type t. Values that do not fit in t wrap around,
+// and if the wrapped values are not contiguous, the range of t is returned.
+func fitRange(r IntRange, t types.Type) IntRange {
+	tr, ok := TypeRange(t)
+	if !ok || (tr.Contains(r.Min) && tr.Contains(r.Max)) {
+		return r
+	}
+	if width, overflow := addInt64(r.Max, -r.Min); r.Min != math.MinInt64 && !overflow && width < tr.Max-tr.Min {
+		lo, _ := wrapInt(int(r.Min), t)
+		hi, _ := wrapInt(int(r.Max), t)
+		if lo <= hi {
+			return IntRange{Min: int64(lo), Max: int64(hi)}
+		}
+	}
+	return tr
+}
+
+func isUnsigned(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsUnsigned != 0
+}
+
+// loopPhiRange returns the range of phi if it is an induction variable of a loop, such as i in
+//
+//	for i := 0; i < n; i++ {}
+//
+// Its range is bounded by the initial values and the loop condition. It is false if phi does not depend on itself.
+func loopPhiRange(phi *ssa.Phi, rangeOf func(v ssa.Value) (IntRange, bool)) ([]IntRange, bool) {
+	var init []ssa.Value
+	var steps []ssa.Value
+	for _, e := range phi.Edges {
+		if dependsOn(e, phi, map[ssa.Value]bool{}) {
+			steps = append(steps, e)
+		} else {
+			init = append(init, e)
+		}
+	}
+	if len(steps) == 0 {
+		return []IntRange{}, false
+	}
+	full, _ := TypeRange(phi.Type())
+	if len(init) == 0 {
+		return []IntRange{full}, true
+	}
+	res, ok := rangeOf(init[0])
+	for _, v := range init[1:] {
+		r, rok := rangeOf(v)
+		res, ok = res.Union(r), ok && rok
+	}
+	if !ok {
+		return []IntRange{full}, true
+	}
+
+	// every step must move in the same direction by a positive amount
+	dir, stepMax := 0, int64(0)
+	for _, s := range steps {
+		b, ok := s.(*ssa.BinOp)
+		if !ok {
+			return []IntRange{full}, true
+		}
+		var d int
+		var amount ssa.Value
+		switch {
+		case b.Op == token.ADD && b.X == phi:
+			d, amount = 1, b.Y
+		case b.Op == token.ADD && b.Y == phi:
+			d, amount = 1, b.X
+		case b.Op == token.SUB && b.X == phi:
+			d, amount = -1, b.Y
+		default:
+			return []IntRange{full}, true
+		}
+		a, ok := rangeOf(amount)
+		if !ok || a.Min <= 0 || (dir != 0 && dir != d) {
+			return []IntRange{full}, true
+		}
+		dir, stepMax = d, max(stepMax, a.Max)
+	}
+
+	// the loop continues while the condition of the header holds
+	ifInstr, ok := phi.Block().Instrs[len(phi.Block().Instrs)-1].(*ssa.If)
+	if !ok {
+		return []IntRange{full}, true
+	}
+	cond, ok := ifInstr.Cond.(*ssa.BinOp)
+	if !ok {
+		return []IntRange{full}, true
+	}
+	for _, s := range steps {
+		if !ifInstr.Block().Succs[0].Dominates(s.(*ssa.BinOp).Block()) {
+			return []IntRange{full}, true // the step is not in the loop body
+		}
+	}
+	op, bound := cond.Op, cond.Y
+	if cond.Y == phi {
+		op, bound = flipComparison(op), cond.X
+	} else if cond.X != phi {
+		return []IntRange{full}, true
+	}
+	br, ok := rangeOf(bound)
+	if !ok {
+		return []IntRange{full}, true
+	}
+	var last int64 // the bound of the last value that satisfies the condition
+	switch {
+	case dir > 0 && op == token.LSS:
+		last = br.Max - 1
+	case dir > 0 && op == token.LEQ:
+		last = br.Max
+	case dir < 0 && op == token.GTR:
+		last = br.Min + 1
+	case dir < 0 && op == token.GEQ:
+		last = br.Min
+	default:
+		return []IntRange{full}, true
+	}
+	if dir > 0 {
+		end, overflow := addInt64(last, stepMax)
+		if overflow || !full.Contains(end) {
+			return []IntRange{full}, true
+		}
+		return []IntRange{{Min: res.Min, Max: max(res.Max, end)}}, true
+	}
+	end, overflow := addInt64(last, -stepMax)
+	if overflow || !full.Contains(end) {
+		return []IntRange{full}, true
+	}
+	return []IntRange{{Min: min(res.Min, end), Max: res.Max}}, true
+}
+
+// dependsOn reports whether v is computed from target by arithmetic, conversions, phis, slicing
+// and the built-in append.
+func dependsOn(v ssa.Value, target ssa.Value, visited map[ssa.Value]bool) bool {
+	if v == target {
+		return true
+	}
+	if visited[v] {
+		return false
+	}
+	visited[v] = true
+	switch t := v.(type) {
+	case *ssa.BinOp:
+		return dependsOn(t.X, target, visited) || dependsOn(t.Y, target, visited)
+	case *ssa.UnOp:
+		return t.Op != token.MUL && dependsOn(t.X, target, visited)
+	case *ssa.Convert:
+		return dependsOn(t.X, target, visited)
+	case *ssa.ChangeType:
+		return dependsOn(t.X, target, visited)
+	case *ssa.Slice:
+		return dependsOn(t.X, target, visited)
+	case *ssa.Call:
+		if b, ok := t.Call.Value.(*ssa.Builtin); ok && b.Name() == "append" {
+			for _, arg := range t.Call.Args {
+				if dependsOn(arg, target, visited) {
+					return true
+				}
+			}
+		}
+	case *ssa.Phi:
+		for _, e := range t.Edges {
+			if dependsOn(e, target, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func flipComparison(op token.Token) token.Token {
+	switch op {
+	case token.LSS:
+		return token.GTR
+	case token.LEQ:
+		return token.GEQ
+	case token.GTR:
+		return token.LSS
+	case token.GEQ:
+		return token.LEQ
+	}
+	return op
+}
+
+// binOpRange returns the range of x op y of the integer type t.
+func binOpRange(op token.Token, x, y IntRange, t types.Type) (IntRange, bool) {
+	full, ok := TypeRange(t)
+	if !ok {
+		return IntRange{}, false
+	}
+	corners := func(f func(a, b int64) (int64, bool)) (IntRange, bool) {
+		var res IntRange
+		for i, ab := range [][2]int64{{x.Min, y.Min}, {x.Min, y.Max}, {x.Max, y.Min}, {x.Max, y.Max}} {
+			r, overflow := f(ab[0], ab[1])
+			if overflow {
+				return full, true
+			}
+			if i == 0 {
+				res = PointRange(r)
+			} else {
+				res = res.Union(PointRange(r))
+			}
+		}
+		return fitRange(res, t), true
+	}
+	switch op {
+	case token.ADD:
+		return corners(addInt64)
+	case token.SUB:
+		return corners(func(a, b int64) (int64, bool) {
+			if b == math.MinInt64 {
+				return 0, true
+			}
+			return addInt64(a, -b)
+		})
+	case token.MUL:
+		return corners(mulInt64)
+	case token.QUO:
+		// division by zero panics, so only the non-zero divisors closest to zero and the extremes matter
+		divisors := make([]int64, 0, 4)
+		for _, d := range []int64{y.Min, y.Max, -1, 1} {
+			if d != 0 && y.Contains(d) {
+				divisors = append(divisors, d)
+			}
+		}
+		if len(divisors) == 0 {
+			return IntRange{}, false
+		}
+		var res IntRange
+		for i, d := range divisors {
+			for _, n := range []int64{x.Min, x.Max} {
+				if n == math.MinInt64 && d == -1 {
+					return full, true
+				}
+				if i == 0 && n == x.Min {
+					res = PointRange(n / d)
+				} else {
+					res = res.Union(PointRange(n / d))
+				}
+			}
+		}
+		return fitRange(res, t), true
+	case token.REM:
+		// the remainder has the sign of the dividend and is smaller than the divisor in magnitude
+		m := max(absInt64(y.Min), absInt64(y.Max))
+		if m == 0 {
+			return IntRange{}, false
+		}
+		m--
+		return IntRange{Min: max(min(x.Min, 0), -m), Max: min(max(x.Max, 0), m)}, true
+	case token.AND:
+		switch {
+		case x.Min >= 0 && y.Min >= 0:
+			return IntRange{Min: 0, Max: min(x.Max, y.Max)}, true
+		case x.Min >= 0:
+			return IntRange{Min: 0, Max: x.Max}, true
+		case y.Min >= 0:
+			return IntRange{Min: 0, Max: y.Max}, true
+		}
+		return full, true
+	case token.OR, token.XOR:
+		if x.Min < 0 || y.Min < 0 {
+			return full, true
+		}
+		upper := bitMask(max(x.Max, y.Max))
+		if op == token.OR {
+			return IntRange{Min: max(x.Min, y.Min), Max: upper}, true
+		}
+		return IntRange{Min: 0, Max: upper}, true
+	case token.AND_NOT:
+		if x.Min >= 0 {
+			return IntRange{Min: 0, Max: x.Max}, true
+		}
+		return full, true
+	case token.SHL:
+		if x.Min < 0 || y.Min < 0 {
+			return full, true
+		}
+		if y.Max >= 63 || x.Max > math.MaxInt64>>y.Max {
+			return full, true
+		}
+		return fitRange(IntRange{Min: x.Min << y.Min, Max: x.Max << y.Max}, t), true
+	case token.SHR:
+		if y.Max < 0 {
+			return full, false // the shift always panics
+		}
+		if y.Min < 0 {
+			y.Min = 0 // negative shift counts panic
+		}
+		lo, hi := min(y.Min, 63), min(y.Max, 63)
+		res := IntRange{Min: x.Min >> hi, Max: x.Max >> lo}
+		if x.Min < 0 {
+			res.Min = x.Min >> lo
+		}
+		if x.Max < 0 {
+			res.Max = x.Max >> hi
+		}
+		return res, true
+	}
+	return IntRange{}, false
+}
+
+// unOpRange returns the range of op x of the integer type t.
+func unOpRange(op token.Token, x IntRange, t types.Type) (IntRange, bool) {
+	full, ok := TypeRange(t)
+	if !ok {
+		return IntRange{}, false
+	}
+	switch op {
+	case token.SUB:
+		if x.Min == math.MinInt64 {
+			return full, true
+		}
+		return fitRange(IntRange{Min: -x.Max, Max: -x.Min}, t), true
+	case token.XOR:
+		if full.Min == 0 { // ^x of an unsigned x is the maximum value minus x
+			return IntRange{Min: full.Max - x.Max, Max: full.Max - x.Min}, true
+		}
+		return IntRange{Min: ^x.Max, Max: ^x.Min}, true
+	}
+	return IntRange{}, false
+}
+
+// intBinOp returns x op y of the integer type t, wrapped around to the width of t.
+func intBinOp(op token.Token, x, y int, t types.Type) (int, bool) {
+	var res int
+	switch op {
+	case token.ADD:
+		res = x + y
+	case token.SUB:
+		res = x - y
+	case token.MUL:
+		res = x * y
+	case token.QUO, token.REM:
+		if y == 0 {
+			return 0, false // panics
+		}
+		if op == token.QUO {
+			res = x / y
+		} else {
+			res = x % y
+		}
+	case token.AND:
+		res = x & y
+	case token.OR:
+		res = x | y
+	case token.XOR:
+		res = x ^ y
+	case token.AND_NOT:
+		res = x &^ y
+	case token.SHL, token.SHR:
+		if y < 0 {
+			return 0, false // panics
+		}
+		if op == token.SHL {
+			res = x << y
+		} else {
+			res = x >> y
+		}
+	default:
+		return 0, false
+	}
+	return wrapInt(res, t)
+}
+
+// intUnOp returns op x of the integer type t, wrapped around to the width of t.
+func intUnOp(op token.Token, x int, t types.Type) (int, bool) {
+	switch op {
+	case token.SUB:
+		return wrapInt(-x, t)
+	case token.XOR:
+		return wrapInt(^x, t)
+	}
+	return 0, false
+}
+
+// wrapInt returns i converted to the integer type t. It is false if t is not an integer type
+// or the result is a 64-bit unsigned value that does not fit in an int.
+func wrapInt(i int, t types.Type) (int, bool) {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || b.Info()&types.IsInteger == 0 {
+		return 0, false
+	}
+	switch b.Kind() {
+	case types.Int8:
+		return int(int8(i)), true
+	case types.Int16:
+		return int(int16(i)), true
+	case types.Int32:
+		return int(int32(i)), true
+	case types.Uint8:
+		return int(uint8(i)), true
+	case types.Uint16:
+		return int(uint16(i)), true
+	case types.Uint32:
+		return int(uint32(i)), true
+	case types.Uint, types.Uint64, types.Uintptr:
+		return i, i >= 0
+	}
+	return i, true
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	return s, (a > 0 && b > 0 && s < 0) || (a < 0 && b < 0 && s >= 0)
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+	p := a * b
+	return p, p/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+}
+
+func absInt64(a int64) int64 {
+	if a == math.MinInt64 {
+		return math.MaxInt64
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// bitMask returns the smallest 2^n-1 that is at least x, for a non-negative x.
+func bitMask(x int64) int64 {
+	return int64(1)<<bits.Len64(uint64(x)) - 1
+}
@@ -0,0 +1,350 @@
+package ssautil
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/tools/go/ssa"
+)
+
+// StringModel models the possible string results of the call c, resolving its arguments with ctx.
+// It returns false if the result is unknown.
+type StringModel func(c CallInfo, ctx *ModelContext) ([]AbstractString, bool)
+
+// IntModel models the possible integer results of the call c, resolving its arguments with ctx.
+// It returns false if the result is unknown.
+type IntModel func(c CallInfo, ctx *ModelContext) ([]int, bool)
+
+// ModelContext resolves the arguments of a modeled call.
+// Arguments are resolved in the domain of the resolution that the call is part of:
+// in ValueToStrings a string argument has no holes, while in ValueToAbstractStrings it may have some.
+type ModelContext struct {
+	call *ssa.Call
+	opts ResolveOptions
+	strs func(v ssa.Value) ([]AbstractString, bool)
+	ints func(v ssa.Value) ([]int, bool)
+	hole func(verb rune) (AbstractString, bool)
+}
+
+// Call returns the call instruction being modeled.
+func (m *ModelContext) Call() *ssa.Call {
+	return m.call
+}
+
+// Options returns the options of the resolution.
+func (m *ModelContext) Options() ResolveOptions {
+	return m.opts
+}
+
+// Strings returns the possible values of the string value v.
+func (m *ModelContext) Strings(v ssa.Value) ([]AbstractString, bool) {
+	return m.strs(v)
+}
+
+// ConstStrings returns the possible values of the string value v. It is false if any of them has a hole.
+func (m *ModelContext) ConstStrings(v ssa.Value) ([]string, bool) {
+	as, ok := m.strs(v)
+	if !ok {
+		return []string{}, false
+	}
+	return constStrings(as)
+}
+
+// Ints returns the possible values of the integer value v.
+func (m *ModelContext) Ints(v ssa.Value) ([]int, bool) {
+	return m.ints(v)
+}
+
+// Hole returns the abstract string that stands for an unknown operand of a formatting verb such as 'd' or 's',
+// or 'v' for any unknown part. In ValueToStrings it is a sample value such as "1" for 'd', and false for verbs
+// without one. In ValueToAbstractStrings it is a hole.
+func (m *ModelContext) Hole(verb rune) (AbstractString, bool) {
+	return m.hole(verb)
+}
+
+// Models maps call patterns to the models of their results.
+// The models added last take precedence. If a model returns false, the models added before it are tried.
+type Models struct {
+	strs []modelEntry[StringModel]
+	ints []modelEntry[IntModel]
+}
+
+type modelEntry[M any] struct {
+	pattern *Pattern
+	model   M
+}
+
+// DefaultModels returns a new copy of the models used by ValueToStrings, ValueToAbstractStrings and ValueToInts
+// if ResolveOptions.Models is nil. Add models to it and set it to ResolveOptions.Models to extend them.
+//
+// The string models cover the formatting functions of the fmt package, strings.Join, the String method of
+// a local strings.Builder or bytes.Buffer, strings.ToUpper, strings.ToLower, strings.TrimSpace,
+// strings.Replace, strings.ReplaceAll, strconv.Itoa, path.Join and path/filepath.Join.
+// The integer model covers the built-in len of strings.
+func DefaultModels() *Models {
+	return builtinModels.Clone()
+}
+
+// builtinModels are the default models, which are never modified.
+var builtinModels = defaultModels()
+
+// NewModels returns an empty set of models.
+func NewModels() *Models {
+	return &Models{}
+}
+
+// Clone returns a copy of m, to which models can be added without affecting m.
+func (m *Models) Clone() *Models {
+	return &Models{strs: slices.Clone(m.strs), ints: slices.Clone(m.ints)}
+}
+
+// AddString adds a model of the string results of the calls matching pattern. See Pattern for the syntax.
+func (m *Models) AddString(pattern string, model StringModel) error {
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		return errors.Wrap(err, "failed to add string model")
+	}
+	m.strs = append(m.strs, modelEntry[StringModel]{pattern: p, model: model})
+	return nil
+}
+
+// AddInt adds a model of the integer results of the calls matching pattern. See Pattern for the syntax.
+func (m *Models) AddInt(pattern string, model IntModel) error {
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		return errors.Wrap(err, "failed to add int model")
+	}
+	m.ints = append(m.ints, modelEntry[IntModel]{pattern: p, model: model})
+	return nil
+}
+
+func (m *Models) stringsOf(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	return applyModels(m.strs, c, ctx)
+}
+
+func (m *Models) intsOf(c CallInfo, ctx *ModelContext) ([]int, bool) {
+	return applyModels(m.ints, c, ctx)
+}
+
+func applyModels[T any, M ~func(c CallInfo, ctx *ModelContext) ([]T, bool)](entries []modelEntry[M], c CallInfo, ctx *ModelContext) ([]T, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].pattern.Match(c) {
+			continue
+		}
+		if res, ok := entries[i].model(c, ctx); ok {
+			return res, true
+		}
+	}
+	return []T{}, false
+}
+
+// modelsOf returns the models of opts.
+func modelsOf(opts ResolveOptions) *Models {
+	if opts.Models == nil {
+		return builtinModels
+	}
+	return opts.Models
+}
+
+// crossOptions returns the options to resolve an argument of a different type than the modeled call.
+// The depth is decreased so that resolutions calling each other terminate.
+func crossOptions(opts ResolveOptions) ResolveOptions {
+	opts.MaxDepth--
+	return opts
+}
+
+func defaultModels() *Models {
+	m := NewModels()
+	must := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+	must(m.AddString("fmt.Sprintf|fmt.Sprint|fmt.Sprintln|fmt.Errorf|fmt.Appendf|fmt.Append|fmt.Appendln", fmtStringModel))
+	must(m.AddString("strings.Join", stringsJoinModel))
+	must(m.AddString("(*strings.Builder).String|(*bytes.Buffer).String", builderStringModel))
+	must(m.AddString("strings.ToUpper", mapLiterals(strings.ToUpper)))
+	must(m.AddString("strings.ToLower", mapLiterals(strings.ToLower)))
+	must(m.AddString("strings.TrimSpace", mapConstStrings(strings.TrimSpace)))
+	must(m.AddString("strings.Replace|strings.ReplaceAll", stringsReplaceModel))
+	must(m.AddString("strconv.Itoa", strconvItoaModel))
+	must(m.AddString("path.Join", joinModel(path.Join)))
+	must(m.AddString("path/filepath.Join", joinModel(filepath.Join)))
+	must(m.AddInt("len", lenModel))
+	return m
+}
+
+// mapLiterals returns a model of a function from a string to a string that applies to each fragment independently.
+func mapLiterals(fn func(s string) string) StringModel {
+	return func(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+		arg, ok := c.ArgOK(0)
+		if !ok {
+			return nil, false
+		}
+		as, ok := ctx.Strings(arg)
+		if !ok {
+			return nil, false
+		}
+		res := make([]AbstractString, 0, len(as))
+		for _, a := range as {
+			mapped := AbstractString{}
+			for _, f := range a {
+				if f.Hole {
+					mapped = mapped.Concat(UnknownString())
+				} else {
+					mapped = mapped.Concat(LiteralString(fn(f.Literal)))
+				}
+			}
+			res = append(res, mapped)
+		}
+		return res, true
+	}
+}
+
+// mapConstStrings returns a model of a function from a string to a string that is only known for constants.
+func mapConstStrings(fn func(s string) string) StringModel {
+	return func(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+		arg, ok := c.ArgOK(0)
+		if !ok {
+			return nil, false
+		}
+		strs, ok := ctx.ConstStrings(arg)
+		if !ok {
+			return nil, false
+		}
+		res := make([]AbstractString, 0, len(strs))
+		for _, s := range strs {
+			res = append(res, LiteralString(fn(s)))
+		}
+		return res, true
+	}
+}
+
+// stringsReplaceModel models strings.Replace and strings.ReplaceAll of constants.
+func stringsReplaceModel(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	args := make([][]string, 3)
+	for i := range args {
+		arg, ok := c.ArgOK(i)
+		if !ok {
+			return nil, false
+		}
+		if args[i], ok = ctx.ConstStrings(arg); !ok {
+			return nil, false
+		}
+	}
+	ns := []int{-1}
+	if arg, ok := c.ArgOK(3); ok { // strings.Replace
+		if ns, ok = ctx.Ints(arg); !ok {
+			return nil, false
+		}
+	}
+	res := make([]AbstractString, 0)
+	for _, s := range args[0] {
+		for _, old := range args[1] {
+			for _, n := range args[2] {
+				for _, cnt := range ns {
+					res = append(res, LiteralString(strings.Replace(s, old, n, cnt)))
+				}
+			}
+		}
+	}
+	return res, true
+}
+
+// strconvItoaModel models strconv.Itoa.
+func strconvItoaModel(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	arg, ok := c.ArgOK(0)
+	if !ok {
+		return nil, false
+	}
+	ints, ok := ctx.Ints(arg)
+	if !ok {
+		return nil, false
+	}
+	res := make([]AbstractString, 0, len(ints))
+	for _, i := range ints {
+		res = append(res, LiteralString(strconv.Itoa(i)))
+	}
+	return res, true
+}
+
+// joinModel returns a model of a variadic function joining constant strings such as path.Join.
+func joinModel(join func(elem ...string) string) StringModel {
+	return func(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+		res := [][]string{{}}
+		for _, arg := range c.Args() {
+			if isSpread(arg) {
+				return nil, false // e.g. path.Join(elems...)
+			}
+			strs, ok := ctx.ConstStrings(arg)
+			if !ok {
+				return nil, false
+			}
+			next := make([][]string, 0, len(res)*len(strs))
+			for _, elems := range res {
+				for _, s := range strs {
+					next = append(next, append(slices.Clip(elems), s))
+				}
+			}
+			if res = next; len(res) > maxFmtResults {
+				return nil, false
+			}
+		}
+		joined := make([]AbstractString, 0, len(res))
+		for _, elems := range res {
+			joined = append(joined, LiteralString(join(elems...)))
+		}
+		return joined, true
+	}
+}
+
+// stringsJoinModel models strings.Join of a slice of strings.
+func stringsJoinModel(c CallInfo, ctx *ModelContext) ([]AbstractString, bool) {
+	sepArg, ok := c.ArgOK(1)
+	if !ok {
+		return nil, false
+	}
+	seps, ok := ctx.Strings(sepArg)
+	if !ok {
+		return nil, false
+	}
+	lists, ok := valueToElems(c.Arg(0), crossOptions(ctx.Options()), ctx.Strings, ctx.Ints)
+	if !ok || len(lists)*len(seps) > maxFmtResults {
+		return nil, false
+	}
+	res := make([]AbstractString, 0, len(lists)*len(seps))
+	for _, elems := range lists {
+		for _, sep := range seps {
+			joined := LiteralString("")
+			for i, e := range elems {
+				if i > 0 {
+					joined = joined.Concat(sep)
+				}
+				joined = joined.Concat(e)
+			}
+			res = append(res, joined)
+		}
+	}
+	return res, true
+}
+
+// lenModel models the built-in len of strings.
+func lenModel(c CallInfo, ctx *ModelContext) ([]int, bool) {
+	arg, ok := c.ArgOK(0)
+	if !ok || !isString(arg.Type()) {
+		return nil, false
+	}
+	strs, ok := ctx.ConstStrings(arg)
+	if !ok {
+		return nil, false
+	}
+	res := make([]int, 0, len(strs))
+	for _, s := range strs {
+		res = append(res, len(s))
+	}
+	return res, true
+}
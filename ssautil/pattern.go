@@ -0,0 +1,609 @@
+package ssautil
+
+import (
+	"fmt"
+	"go/types"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/tools/go/ssa"
+)
+
+// Pattern is a compiled call pattern that matches the callee of any kind of CallInfo.
+//
+// A pattern is one or more alternatives separated by "|". Each alternative is one of
+//
+//	append                     built-in function
+//	fmt.Println                function (package path and function name)
+//	(*pkg/path.Type).Method    static method
+//	pkg/path.Interface.Method  dynamic (interface) method
+//	error.Error                built-in interface method
+//
+// Every package path, type name and function name is a segment, which is one of
+//
+//	Name           literal
+//	*              any
+//	Na*e, N?me     glob, where "*" does not match "/" in package paths
+//	{regexp}       regular expression matching the whole segment
+//	github.com/... package path prefix, matching the package and all its sub packages
+//
+// The receiver of a static method matches both pointer and non-pointer receivers.
+// Prefix it with "=" to match it exactly: "(=*pkg.T).M" only matches pointer receivers and "(=pkg.T).M" only value receivers.
+//
+// Function names, type names and receivers may be followed by type arguments such as "slices.Contains[[]string]".
+// A type argument is either a type string as printed by types.TypeString, "*" to match any type, or {regexp}.
+// Like a partial instantiation in Go, the trailing type arguments may be omitted and then match any type.
+// Without type arguments a pattern matches any instantiation.
+//
+// An alternative may be followed by constraints on the arguments of the call, such as
+//
+//	os.Setenv("PATH", _)
+//	(*database/sql.DB).Query(prefix("SELECT"), ...)
+//
+// See ArgConstraint for the constraints. Without an argument list any arguments match.
+//
+// An alternative may be prefixed with "go " or "defer " to only match a CallSite of a go or defer statement,
+// such as "go (*sync.WaitGroup).Wait". Without a prefix any call mode matches.
+type Pattern struct {
+	src  string
+	alts []*patternAlt
+}
+
+type patternAlt struct {
+	src string // the alternative without the mode prefix and the argument list
+	// call mode of the go or defer prefix, nil if any call mode matches
+	mode *CallMode
+	// static method
+	method  bool
+	exact   bool // exact receiver pointer-ness
+	pointer bool
+	recv    *patternName
+	// builtin function, function, interface method and builtin interface method
+	parts []*patternName
+	// package paths of function (parts[:len-1]) and interface method (parts[:len-2]) respectively
+	funcPkg, ifacePkg *patternSegment
+	// argument constraints, nil if any arguments match
+	args     []*ArgConstraint
+	restArgs bool // args ends with "..."
+}
+
+type patternName struct {
+	seg      *patternSegment
+	typeArgs []*patternSegment // nil if any instantiation
+}
+
+type patternSegment struct {
+	any bool
+	lit string
+	re  *regexp.Regexp
+}
+
+// ParsePattern compiles a call pattern. See Pattern for the syntax.
+func ParsePattern(s string) (*Pattern, error) {
+	alts, err := splitTopLevel(s, '|')
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %q", s)
+	}
+	p := &Pattern{src: s}
+	for _, a := range alts {
+		alt, err := parseAlt(strings.TrimSpace(a))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", s)
+		}
+		p.alts = append(p.alts, alt)
+	}
+	return p, nil
+}
+
+// MustParsePattern is like ParsePattern but panics if the pattern is malformed.
+func MustParsePattern(s string) *Pattern {
+	p, err := ParsePattern(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) String() string {
+	return p.src
+}
+
+// Match reports whether the callee and the arguments of c match any alternative of the pattern.
+// A DynamicFunctionCall matches if all its resolved targets match.
+// Alternatives with a call mode prefix only match if c is a CallSite of the mode.
+func (p *Pattern) Match(c CallInfo) bool {
+	inner := c
+	if s, ok := c.(*CallSite); ok {
+		inner = s.CallInfo
+	}
+	if d, ok := inner.(*DynamicFunctionCall); ok {
+		targets, _ := d.Targets()
+		return p.matchFuncs(targets, c, MatchAll)
+	}
+	return p.match(c.callee(), c)
+}
+
+// MatchFunc reports whether a static call to fn matches the pattern, ignoring argument constraints.
+func (p *Pattern) MatchFunc(fn *ssa.Function) bool {
+	return p.match(FuncCallInfo(fn).callee(), nil)
+}
+
+// match reports whether ce and the arguments of c match any alternative.
+// If c is nil, only alternatives without argument constraints match.
+func (p *Pattern) match(ce *callee, c CallInfo) bool {
+	for _, alt := range p.alts {
+		if alt.match(ce) && alt.matchArgs(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFuncs matches fns as the possible callees of c in the given mode.
+func (p *Pattern) matchFuncs(fns []*ssa.Function, c CallInfo, mode MatchMode) bool {
+	if len(fns) == 0 {
+		return false
+	}
+	for _, fn := range fns {
+		ce := FuncCallInfo(fn).callee()
+		if s, ok := c.(*CallSite); ok {
+			ce.setMode(s.Mode)
+		}
+		if p.match(ce, c) == (mode == MatchAny) {
+			return mode == MatchAny
+		}
+	}
+	return mode == MatchAll
+}
+
+var patternCache sync.Map // map[string]*Pattern, nil for malformed patterns
+
+// cachedPattern parses the pattern string once and caches it. It returns nil for malformed patterns.
+func cachedPattern(namePattern string) *Pattern {
+	if cached, ok := patternCache.Load(namePattern); ok {
+		return cached.(*Pattern)
+	}
+	p, _ := ParsePattern(namePattern)
+	patternCache.Store(namePattern, p)
+	return p
+}
+
+// matchPattern matches c against the pattern string. Malformed patterns never match.
+func matchPattern(c CallInfo, namePattern string) bool {
+	p := cachedPattern(namePattern)
+	return p != nil && p.Match(c)
+}
+
+// callModePrefixes are the prefixes of an alternative that select the mode of the calls it matches.
+var callModePrefixes = []struct {
+	prefix string
+	mode   CallMode
+}{
+	{"go ", CallGo},
+	{"defer ", CallDeferred},
+}
+
+func parseAlt(s string) (*patternAlt, error) {
+	if s == "" {
+		return nil, errors.New("empty alternative")
+	}
+	alt := &patternAlt{}
+	src := s
+	for _, p := range callModePrefixes {
+		if rest, ok := strings.CutPrefix(s, p.prefix); ok {
+			mode := p.mode
+			alt.mode, s = &mode, strings.TrimSpace(rest)
+			break
+		}
+	}
+	for _, p := range callModePrefixes {
+		if alt.mode != nil && strings.HasPrefix(s, p.prefix) {
+			return nil, errors.Newf("more than one call mode in %q", src)
+		}
+	}
+	if s == "" {
+		return nil, errors.New("empty alternative")
+	}
+	if open := argListIndex(s); open > 0 {
+		var err error
+		if alt.args, alt.restArgs, err = parseArgConstraints(s[open+1 : len(s)-1]); err != nil {
+			return nil, err
+		}
+		s = strings.TrimSpace(s[:open])
+	}
+	alt.src = s
+
+	if s[0] == '(' {
+		end, err := closingIndex(s, 0)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(s[end+1:], ".") {
+			return nil, errors.Newf("method name is expected after receiver in %q", s)
+		}
+		name, err := parseName(s[end+2:])
+		if err != nil {
+			return nil, err
+		}
+		recv := s[1:end]
+		if alt.exact = strings.HasPrefix(recv, "="); alt.exact {
+			recv = recv[1:]
+		}
+		// "(*.T)" is a non-pointer receiver of any package rather than a pointer receiver of the package "".
+		if rest, ok := strings.CutPrefix(recv, "*"); ok && !strings.HasPrefix(rest, ".") {
+			alt.pointer, recv = true, rest
+		}
+		parts, err := splitTopLevel(recv, '.')
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) < 2 {
+			return nil, errors.Newf("receiver %q must be qualified by its package", s[1:end])
+		}
+		if alt.funcPkg, err = parseSegment(strings.Join(parts[:len(parts)-1], "."), true); err != nil {
+			return nil, err
+		}
+		if alt.recv, err = parseName(parts[len(parts)-1]); err != nil {
+			return nil, err
+		}
+		alt.method = true
+		alt.parts = []*patternName{name}
+		return alt, nil
+	}
+
+	parts, err := splitTopLevel(s, '.')
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		name, err := parseName(part)
+		if err != nil {
+			return nil, err
+		}
+		alt.parts = append(alt.parts, name)
+	}
+	if len(parts) >= 2 {
+		if alt.funcPkg, err = parseSegment(strings.Join(parts[:len(parts)-1], "."), true); err != nil {
+			return nil, err
+		}
+	}
+	if len(parts) >= 3 {
+		if alt.ifacePkg, err = parseSegment(strings.Join(parts[:len(parts)-2], "."), true); err != nil {
+			return nil, err
+		}
+	}
+	return alt, nil
+}
+
+// parseName parses a segment optionally followed by type arguments.
+func parseName(s string) (*patternName, error) {
+	name := &patternName{}
+	i := strings.IndexByte(s, '[')
+	if strings.HasPrefix(s, "{") {
+		end, err := closingIndex(s, 0)
+		if err != nil {
+			return nil, err
+		}
+		i = -1
+		if end+1 < len(s) {
+			i = end + 1
+		}
+	}
+	if i >= 0 {
+		if s[i] != '[' || !strings.HasSuffix(s, "]") {
+			return nil, errors.Newf("unexpected character after segment in %q", s)
+		}
+		args, err := splitTopLevel(s[i+1:len(s)-1], ',')
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range args {
+			a = strings.TrimSpace(a)
+			if a == "" {
+				return nil, errors.Newf("empty type argument in %q", s)
+			}
+			seg := &patternSegment{any: a == "*", lit: a}
+			if strings.HasPrefix(a, "{") {
+				var err error
+				if seg, err = parseSegment(a, false); err != nil {
+					return nil, err
+				}
+			}
+			name.typeArgs = append(name.typeArgs, seg)
+		}
+		s = s[:i]
+	}
+	seg, err := parseSegment(s, false)
+	if err != nil {
+		return nil, err
+	}
+	name.seg = seg
+	return name, nil
+}
+
+// parseSegment parses a literal, glob, regexp or (if path is true) package path prefix segment.
+func parseSegment(s string, path bool) (*patternSegment, error) {
+	switch {
+	case s == "":
+		return nil, errors.New("empty segment")
+	case s == "*":
+		return &patternSegment{any: true}, nil
+	case strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}"):
+		re, err := regexp.Compile(`^(?:` + s[1:len(s)-1] + `)$`)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regexp segment %q", s)
+		}
+		return &patternSegment{re: re}, nil
+	case path && (s == "..." || strings.HasSuffix(s, "/...")):
+		prefix := strings.TrimSuffix(strings.TrimSuffix(s, "..."), "/")
+		if prefix == "" {
+			return &patternSegment{any: true}, nil
+		}
+		return &patternSegment{re: regexp.MustCompile(`^` + regexp.QuoteMeta(prefix) + `(?:/.*)?$`)}, nil
+	case strings.ContainsAny(s, "*?"):
+		star := ".*"
+		if path {
+			star = "[^/]*"
+		}
+		var b strings.Builder
+		b.WriteString("^")
+		for _, r := range s {
+			switch r {
+			case '*':
+				b.WriteString(star)
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString("$")
+		return &patternSegment{re: regexp.MustCompile(b.String())}, nil
+	case strings.ContainsAny(s, "{}[]()|, "):
+		return nil, errors.Newf("unexpected character in segment %q", s)
+	default:
+		return &patternSegment{lit: s}, nil
+	}
+}
+
+// argListIndex returns the index of the "(" of the argument list at the end of the alternative s, or -1 if none.
+func argListIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '`':
+			end, err := quoteEnd(s, i)
+			if err != nil {
+				return -1
+			}
+			i = end
+		case '(', '[', '{':
+			end, err := closingIndex(s, i)
+			if err != nil {
+				return -1
+			}
+			if c == '(' && i > 0 && end == len(s)-1 {
+				return i
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// splitTopLevel splits s by sep outside of (), [], {} and quoted strings.
+// "..." that follows "/" or starts s is not split by '.'.
+func splitTopLevel(s string, sep byte) ([]string, error) {
+	res := make([]string, 0)
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '`':
+			end, err := quoteEnd(s, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '(' || c == '[' || c == '{':
+			end, err := closingIndex(s, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == ')' || c == ']' || c == '}':
+			return nil, errors.Newf("unbalanced %q in %q", c, s)
+		case c == '.' && sep == '.' && strings.HasPrefix(s[i:], "...") && (i == 0 || s[i-1] == '/'):
+			i += 2
+		case c == sep:
+			res = append(res, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(res, s[start:]), nil
+}
+
+// closingIndex returns the index of the bracket closing the one at s[open].
+// Brackets inside {} are not counted except for nested {}, so that regular expressions may contain any of them.
+func closingIndex(s string, open int) (int, error) {
+	stack := []byte{s[open]}
+	for i := open + 1; i < len(s); i++ {
+		top := stack[len(stack)-1]
+		switch c := s[i]; {
+		case top == '{' && c == '{':
+			stack = append(stack, c)
+		case top == '{' && c != '}':
+		case c == '"' || c == '`':
+			end, err := quoteEnd(s, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, c)
+		case c == ')' || c == ']' || c == '}':
+			if openingBrackets[c] != top {
+				return 0, errors.Newf("unbalanced %q in %q", c, s)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, errors.Newf("unclosed %q in %q", s[open], s)
+}
+
+var openingBrackets = map[byte]byte{')': '(', ']': '[', '}': '{'}
+
+// quoteEnd returns the index of the quote closing the Go string literal starting at s[open].
+func quoteEnd(s string, open int) (int, error) {
+	for i := open + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if s[open] == '"' {
+				i++
+			}
+		case s[open]:
+			return i, nil
+		}
+	}
+	return 0, errors.Newf("unclosed string literal in %q", s)
+}
+
+func (alt *patternAlt) match(ce *callee) bool {
+	if alt.mode != nil && (ce.mode == nil || *ce.mode != *alt.mode) {
+		return false
+	}
+	if ce.embedded != nil && alt.match(ce.embedded) {
+		return true
+	}
+	if alt.src == ce.full {
+		return true
+	}
+	last := alt.parts[len(alt.parts)-1]
+	switch ce.kind {
+	case calleeBuiltin:
+		return !alt.method && len(alt.parts) == 1 && last.match(ce.name, nil)
+	case calleeFunc:
+		return !alt.method && len(alt.parts) >= 2 && alt.funcPkg.match(ce.pkg) && last.match(ce.name, ce.typeArgs)
+	case calleeBuiltinMethod:
+		return !alt.method && len(alt.parts) == 2 && alt.parts[0].match(ce.recv, nil) && last.match(ce.name, nil)
+	case calleeInterfaceMethod:
+		return !alt.method && len(alt.parts) >= 3 && alt.ifacePkg.match(ce.pkg) &&
+			alt.parts[len(alt.parts)-2].match(ce.recv, ce.recvTypeArgs) && last.match(ce.name, ce.typeArgs)
+	case calleeMethod:
+		return alt.method && (!alt.exact || alt.pointer == ce.pointer) && alt.funcPkg.match(ce.pkg) &&
+			alt.recv.match(ce.recv, ce.recvTypeArgs) && last.match(ce.name, ce.typeArgs)
+	}
+	return false
+}
+
+func (n *patternName) match(name string, typeArgs []types.Type) bool {
+	if !n.seg.match(name) {
+		return false
+	}
+	if n.typeArgs == nil {
+		return true
+	}
+	if len(n.typeArgs) > len(typeArgs) {
+		return false
+	}
+	for i, targ := range n.typeArgs {
+		if !targ.match(types.TypeString(typeArgs[i], nil)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *patternSegment) match(v string) bool {
+	switch {
+	case s.any:
+		return true
+	case s.re != nil:
+		return s.re.MatchString(v)
+	default:
+		return s.lit == v
+	}
+}
+
+type calleeKind int
+
+const (
+	calleeUnknown calleeKind = iota
+	calleeBuiltin
+	calleeFunc
+	calleeMethod
+	calleeInterfaceMethod
+	calleeBuiltinMethod
+)
+
+// callee describes the callee of a CallInfo for pattern matching.
+type callee struct {
+	kind         calleeKind
+	full         string // the name of the CallInfo
+	pkg          string
+	recv         string // receiver type name without package, pointer and type arguments
+	pointer      bool
+	recvTypeArgs []types.Type
+	name         string
+	typeArgs     []types.Type
+	mode         *CallMode // nil if unknown
+	embedded     *callee   // the declaring interface of a method of an embedded interface
+}
+
+// setRecv sets the receiver of ce from the receiver type t.
+func (ce *callee) setRecv(t types.Type) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		ce.pointer, t = true, ptr.Elem()
+	}
+	if named, ok := t.(*types.Named); ok {
+		ce.recv = named.Obj().Name()
+		ce.recvTypeArgs = namedTypeArgs(named)
+		return
+	}
+	ce.recv = strings.TrimPrefix(t.String(), ce.pkg+".")
+}
+
+func (ce *callee) setMode(mode CallMode) {
+	for e := ce; e != nil; e = e.embedded {
+		e.mode = &mode
+	}
+}
+
+func (s *StaticMethodCall) callee() *callee {
+	ce := &callee{kind: calleeMethod, full: s.Name(), pkg: s.Pkg().Path(), name: s.Method().Name()}
+	ce.setRecv(s.Func().Signature.Recv().Type())
+	return ce
+}
+func (d *DynamicMethodCall) callee() *callee {
+	ce := &callee{kind: calleeInterfaceMethod, full: d.Name(), pkg: d.Pkg().Path(), name: d.Method().Name()}
+	ce.setRecv(d.m.recvType())
+	// A method of an embedded interface also matches the interface that declares it.
+	if decl := d.Method().Type().(*types.Signature).Recv().Type(); !types.Identical(decl, d.m.recvType()) {
+		ce.embedded = &callee{kind: calleeInterfaceMethod, full: fmt.Sprintf("%s.%s", decl, d.Method().Name()), pkg: d.Pkg().Path(), name: d.Method().Name()}
+		ce.embedded.setRecv(decl)
+	}
+	return ce
+}
+func (b *BuiltinDynamicMethodCall) callee() *callee {
+	return &callee{kind: calleeBuiltinMethod, full: b.Name(), recv: b.m.recvType().String(), name: b.Method().Name()}
+}
+func (s *StaticFunctionCall) callee() *callee {
+	return &callee{kind: calleeFunc, full: s.Name(), pkg: s.Pkg().Path(), name: s.Func().Name(), typeArgs: s.TypeArgs()}
+}
+func (b *BuiltinStaticFunctionCall) callee() *callee {
+	return &callee{kind: calleeBuiltin, full: b.Name(), name: b.Name()}
+}
+func (s *StaticFunctionClosureCall) callee() *callee {
+	return &callee{kind: calleeFunc, full: s.Name(), pkg: s.Pkg().Path(), name: s.Func().Name()}
+}
+func (s *CallSite) callee() *callee {
+	ce := s.CallInfo.callee()
+	ce.setMode(s.Mode)
+	return ce
+}
+func (d *DynamicFunctionCall) callee() *callee {
+	return &callee{kind: calleeUnknown, full: d.Name()}
+}
@@ -0,0 +1,142 @@
+package ssautil_test
+
+import (
+	"testing"
+
+	"github.com/haijima/analysisutil/ssautil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestParsePattern(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"fmt.Println|",
+		"(fmt.Stringer.String",
+		"(Foo).String",
+		"(*pkg.Foo)String",
+		"fmt.{Sprint(}",
+		"slices.Contains[]",
+		"slices.Contains[int",
+		"fmt.Sprint]",
+	} {
+		_, err := ssautil.ParsePattern(s)
+		assert.Error(t, err, s)
+	}
+	for range 20 {
+		_, err := ssautil.ParsePattern("go defer fmt.Println")
+		assert.Error(t, err)
+		_, err = ssautil.ParsePattern("defer go fmt.Println")
+		assert.Error(t, err)
+		_, err = ssautil.ParsePattern("go go fmt.Println")
+		assert.Error(t, err)
+	}
+	assert.Panics(t, func() { ssautil.MustParsePattern("fmt.{") })
+	assert.Equal(t, "fmt.Println|fmt.Printf", ssautil.MustParsePattern("fmt.Println|fmt.Printf").String())
+}
+
+func TestPattern_Match(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/call", "./...")
+	require.NoError(t, err)
+
+	calls := make(map[string]ssautil.CallInfo)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			c := ssautil.GetCallInfo(call.Common())
+			calls[c.Name()] = c
+		}
+	}
+	const pkg = "github.com/haijima/analysisutil/ssautil/testdata/src/call"
+
+	tests := []struct {
+		call    string
+		pattern string
+		want    bool
+	}{
+		{"fmt.Println", "fmt.Println", true},
+		{"fmt.Println", "fmt.Print*", true},
+		{"fmt.Println", "fmt.{Print(ln|f)}", true},
+		{"fmt.Println", "fmt.{Print}", false},
+		{"fmt.Println", "log.Println|fmt.Println", true},
+		{"fmt.Println", "f*.Println", true},
+		{"fmt.Println", "fmt.Println.*", false},
+		{pkg + ".foo", "github.com/haijima/....foo", true},
+		{pkg + ".foo", "github.com/haijima/analysisutil/ssautil/testdata/src/call/....foo", true},
+		{pkg + ".foo", "github.com/haijima/other/....foo", false},
+		{pkg + ".foo", "github.com/*/analysisutil/ssautil/testdata/src/call.foo", true},
+		{pkg + ".foo", "github.com/*.foo", false},
+		{pkg + ".foo", "*.foo[" + pkg + ".Stringer]", true},
+		{pkg + ".foo", "*.foo[*]", true},
+		{pkg + ".foo", "*.foo[{.*Stringer}]", true},
+		{pkg + ".foo", "*.foo[int]", false},
+		{pkg + ".foo", "*.foo[*, *]", false},
+		{"(" + pkg + ".Foo).String", "(*" + pkg + ".Foo).String", true},
+		{"(" + pkg + ".Foo).String", "(=" + pkg + ".Foo).String", true},
+		{"(" + pkg + ".Foo).String", "(=*" + pkg + ".Foo).String", false},
+		{"(*" + pkg + ".Fizz).String", "(=*" + pkg + ".Fizz).String", true},
+		{"(*" + pkg + ".Fizz).String", "(=" + pkg + ".Fizz).String", false},
+		{"(*" + pkg + ".Fizz).String", "(*github.com/....{F.*}).{Str.*}", true},
+		{"(*" + pkg + ".Fizz).String", pkg + ".Fizz.String", false},
+		{pkg + ".Barer.Bar", "github.com/....Barer.Bar", true},
+		{pkg + ".Barer.Bar", "*.B*.B*", true},
+		{pkg + ".Barer.Bar", "(*.Barer).Bar", false},
+		{"error.Error", "error.Error|append", true},
+		{"append", "error.Error|append", true},
+		{"append", "app*", true},
+	}
+	for _, tt := range tests {
+		c, ok := calls[tt.call]
+		require.True(t, ok, tt.call)
+		assert.Equal(t, tt.want, ssautil.MustParsePattern(tt.pattern).Match(c), "%s %s", tt.call, tt.pattern)
+		assert.Equal(t, tt.want, c.Match(tt.pattern), "%s %s", tt.call, tt.pattern)
+	}
+}
+
+func TestPattern_MatchArgs(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/args", "./...")
+	require.NoError(t, err)
+
+	calls := make([]ssautil.CallInfo, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			calls = append(calls, ssautil.GetCallInfo(call.Common()))
+		}
+	}
+	require.Equal(t, 5, len(calls))
+
+	tests := []struct {
+		pattern string
+		want    []bool
+	}{
+		{"(*database/sql.DB).Query", []bool{true, true, false, false, false}},
+		{`(*database/sql.DB).Query(prefix("SELECT"), ...)`, []bool{true, false, false, false, false}},
+		{`(*database/sql.DB).Query(regexp("^(SELECT|DELETE) "), _)`, []bool{true, true, false, false, false}},
+		{`(*database/sql.DB).Query(contains("users"))`, []bool{false, false, false, false, false}},
+		{`os.Setenv("PATH", *)`, []bool{false, false, true, false, false}},
+		{`os.Setenv(_, suffix("bin"))`, []bool{false, false, true, false, false}},
+		{`os.Setenv(_, _)`, []bool{false, false, true, true, false}},
+		{`os.Setenv(...)`, []bool{false, false, true, true, false}},
+		{`os.Setenv(_, "/root")`, []bool{false, false, false, false, false}},
+		{`strings.Repeat("-", 10)`, []bool{false, false, false, false, true}},
+		{`strings.Repeat(_, >=10)`, []bool{false, false, false, false, true}},
+		{`strings.Repeat(_, <10)`, []bool{false, false, false, false, false}},
+		{`os.Setenv("PATH", _)|strings.Repeat(_, !=0)`, []bool{false, false, true, false, true}},
+		{`os.Setenv(key="HOME")`, []bool{false, false, false, true, false}},
+		{`os.Setenv(value=prefix("/"), key="PATH")`, []bool{false, false, true, false, false}},
+		{`strings.Repeat("-", count=>1)`, []bool{false, false, false, false, true}},
+		{`(*database/sql.DB).Query(query=prefix("DELETE"))`, []bool{false, true, false, false, false}},
+		{`os.Setenv(name="PATH")`, []bool{false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		p := ssautil.MustParsePattern(tt.pattern)
+		for i, c := range calls {
+			assert.Equal(t, tt.want[i], p.Match(c), "%s %s", tt.pattern, c.Name())
+		}
+	}
+
+	for _, s := range []string{`os.Setenv("PATH)`, `os.Setenv(unknown("x"))`, `os.Setenv(..., _)`, `os.Setenv(=>1)`, `os.Setenv(regexp("("))`, `os.Setenv(key="PATH", _)`} {
+		_, err := ssautil.ParsePattern(s)
+		assert.Error(t, err, s)
+	}
+}
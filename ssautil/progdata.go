@@ -0,0 +1,95 @@
+package ssautil
+
+import (
+	"go/types"
+	"slices"
+	"sync"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// maxCachedPrograms is the number of the most recently used programs whose derived data is cached.
+const maxCachedPrograms = 4
+
+// programData is the data derived from all the functions of a program, computed once when it is first needed.
+// Packages created in the program after that are not reflected.
+type programData struct {
+	prog *ssa.Program
+
+	funcsOnce sync.Once
+	funcs     []*ssa.Function
+
+	ifaceOnce  sync.Once
+	ifaceTypes []types.Type
+
+	sitesOnce sync.Once
+	sites     map[*ssa.Function][]ssa.CallInstruction
+}
+
+var programCache struct {
+	mu   sync.Mutex
+	data []*programData // the most recently used last
+}
+
+// dataOf returns the derived data of prog.
+func dataOf(prog *ssa.Program) *programData {
+	programCache.mu.Lock()
+	defer programCache.mu.Unlock()
+	for i, d := range programCache.data {
+		if d.prog == prog {
+			programCache.data = append(slices.Delete(programCache.data, i, i+1), d)
+			return d
+		}
+	}
+	d := &programData{prog: prog}
+	if len(programCache.data) == maxCachedPrograms {
+		programCache.data = slices.Delete(programCache.data, 0, 1)
+	}
+	programCache.data = append(programCache.data, d)
+	return d
+}
+
+// functions returns the functions declared in the packages of the program.
+func (d *programData) functions() []*ssa.Function {
+	d.funcsOnce.Do(func() {
+		for _, pkg := range d.prog.AllPackages() {
+			d.funcs = append(d.funcs, packageFunctions(pkg)...)
+		}
+	})
+	return d.funcs
+}
+
+// interfaceTypes returns the non-interface types converted to an interface in the program.
+func (d *programData) interfaceTypes() []types.Type {
+	d.ifaceOnce.Do(func() {
+		d.ifaceTypes = makeInterfaceTypes(d.functions())
+	})
+	return d.ifaceTypes
+}
+
+// callSites returns the call sites in the program that statically call each function,
+// either directly or through a closure of the function.
+func (d *programData) callSites() map[*ssa.Function][]ssa.CallInstruction {
+	d.sitesOnce.Do(func() {
+		d.sites = make(map[*ssa.Function][]ssa.CallInstruction)
+		for _, f := range d.functions() {
+			for _, b := range f.Blocks {
+				for _, instr := range b.Instrs {
+					site, ok := instr.(ssa.CallInstruction)
+					if !ok || site.Common().IsInvoke() {
+						continue
+					}
+					switch callee := site.Common().Value.(type) {
+					case *ssa.Function:
+						d.sites[callee] = append(d.sites[callee], site)
+					case *ssa.MakeClosure:
+						if fn, ok := callee.Fn.(*ssa.Function); ok {
+							d.sites[fn] = append(d.sites[fn], site)
+						}
+					}
+				}
+			}
+		}
+	})
+	return d.sites
+}
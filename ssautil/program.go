@@ -0,0 +1,68 @@
+package ssautil
+
+import (
+	"slices"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	xssautil "golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Program is a single SSA program built from loaded packages and all their transitive dependencies.
+// Functions from different packages belong to the same ssa.Program, so they are comparable
+// and calls can be followed across package boundaries.
+type Program struct {
+	Prog *ssa.Program
+	// Packages are the SSA packages of the loaded packages, in the same order.
+	Packages []*buildssa.SSA
+
+	byPath map[string]*buildssa.SSA
+}
+
+// BuildProgram builds pkgs and their transitive dependencies into a single ssa.Program.
+// The packages must have been loaded with syntax for all dependencies, e.g. by analysisutil.LoadPackages.
+func BuildProgram(pkgs []*packages.Package, mode ssa.BuilderMode) (*Program, error) {
+	prog, ssapkgs := xssautil.AllPackages(pkgs, mode)
+	for i, ssapkg := range ssapkgs {
+		if ssapkg == nil {
+			return nil, errors.Newf("failed to build SSA of %s", pkgs[i].PkgPath)
+		}
+	}
+	prog.Build()
+
+	p := &Program{Prog: prog, Packages: make([]*buildssa.SSA, 0, len(pkgs)), byPath: make(map[string]*buildssa.SSA)}
+	byID := make(map[string]*buildssa.SSA) // test variants share the path of the package they test
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if ssapkg := prog.Package(pkg.Types); ssapkg != nil {
+			s := &buildssa.SSA{Pkg: ssapkg, SrcFuncs: srcFuncs(pkg, ssapkg)}
+			byID[pkg.ID] = s
+			if _, ok := p.byPath[pkg.PkgPath]; !ok || pkg.ID == pkg.PkgPath {
+				p.byPath[pkg.PkgPath] = s
+			}
+		}
+	})
+	for _, pkg := range pkgs {
+		p.Packages = append(p.Packages, byID[pkg.ID])
+	}
+	return p, nil
+}
+
+// Package returns the SSA package and source functions of the package with the given path.
+// Dependencies of the loaded packages can also be looked up.
+// If test variants were loaded, the package without its test files is returned.
+func (p *Program) Package(path string) (*buildssa.SSA, bool) {
+	s, ok := p.byPath[path]
+	return s, ok
+}
+
+// PackagePaths returns the paths of all packages in the program.
+func (p *Program) PackagePaths() []string {
+	paths := make([]string, 0, len(p.byPath))
+	for path := range p.byPath {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
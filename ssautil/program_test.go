@@ -0,0 +1,61 @@
+package ssautil_test
+
+import (
+	"testing"
+
+	"github.com/haijima/analysisutil"
+	"github.com/haijima/analysisutil/ssautil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestBuildProgram(t *testing.T) {
+	prog, err := ssautil.LoadProgram("./testdata/src/program", "./...")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, len(prog.Packages))
+	assert.Contains(t, prog.PackagePaths(), "strings")
+	main, ok := prog.Package("github.com/haijima/analysisutil/ssautil/testdata/src/program")
+	require.True(t, ok)
+	sub, ok := prog.Package("github.com/haijima/analysisutil/ssautil/testdata/src/program/sub")
+	require.True(t, ok)
+	assert.Same(t, main.Pkg.Prog, sub.Pkg.Prog)
+	require.Equal(t, 1, len(sub.SrcFuncs))
+
+	var callee *ssa.Function
+	for _, fn := range main.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if call, ok := instr.(*ssa.Call); ok {
+					callee = call.Call.StaticCallee()
+				}
+			}
+		}
+	}
+	assert.Same(t, sub.SrcFuncs[0], callee)
+}
+
+func TestBuildProgram_Tests(t *testing.T) {
+	pkgs, err := analysisutil.LoadPackagesWithConfig(&analysisutil.LoadConfig{Dir: "./testdata/src/program", Tests: true}, "./...")
+	require.NoError(t, err)
+	prog, err := ssautil.BuildProgram(pkgs, ssa.BuilderMode(0))
+	require.NoError(t, err)
+
+	require.Equal(t, len(pkgs), len(prog.Packages))
+	const sub = "github.com/haijima/analysisutil/ssautil/testdata/src/program/sub"
+	found := false
+	for i, pkg := range pkgs {
+		require.NotNil(t, prog.Packages[i], pkg.ID)
+		assert.Same(t, pkg.Types, prog.Packages[i].Pkg.Pkg, pkg.ID)
+		if pkg.ID == sub+" ["+sub+".test]" {
+			assert.Equal(t, 2, len(prog.Packages[i].SrcFuncs)) // Query and TestQuery
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	s, ok := prog.Package(sub)
+	require.True(t, ok)
+	assert.Equal(t, 1, len(s.SrcFuncs))
+}
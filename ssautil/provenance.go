@@ -0,0 +1,150 @@
+package ssautil
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Origin is a value that a resolved value is derived from.
+type Origin struct {
+	Value ssa.Value
+	// Pos is the position of Value. For a constant it is the position of its literal if it is found in the function
+	// using the constant, or the position of the instruction using it otherwise.
+	Pos *Posx
+	// Pred is the predecessor block that Value flows from if Value is an edge of a Phi, or nil otherwise.
+	Pred *ssa.BasicBlock
+}
+
+// IsLiteral reports whether the origin is a constant.
+func (o Origin) IsLiteral() bool {
+	_, ok := o.Value.(*ssa.Const)
+	return ok
+}
+
+// Traced is a resolved value with the values it is derived from.
+type Traced[T any] struct {
+	Value T
+	// Path is the values followed from the resolved value to the constants it is made of, in the order they are followed.
+	// The operands of a value such as a concatenation or a modeled call follow the value.
+	Path []Origin
+}
+
+// Literals returns the constants that the value is made of, which are the literals to change to change the value.
+func (t Traced[T]) Literals() []Origin {
+	res := make([]Origin, 0)
+	for _, o := range t.Path {
+		if o.IsLiteral() {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// ValueToStringsTraced is like ValueToStrings but also returns the values that each string is derived from,
+// such as the literals, the edges of Phi and the fmt.Sprintf calls.
+// The path of a result of a modeled call such as fmt.Sprintf includes all the arguments that the model resolved.
+func ValueToStringsTraced(v ssa.Value) ([]Traced[string], bool) {
+	return ValueToStringsTracedWithOptions(v, DefaultResolveOptions())
+}
+
+func ValueToStringsTracedWithOptions(v ssa.Value, opts ResolveOptions) ([]Traced[string], bool) {
+	f := &stringFlattener[Traced[string]]{
+		opts: opts,
+		str:  func(t Traced[string]) string { return t.Value },
+		derive: func(s string, from ...Traced[string]) Traced[string] {
+			var path []Origin
+			for _, t := range from {
+				path = concatPaths(path, t.Path)
+			}
+			return Traced[string]{Value: s, Path: path}
+		},
+	}
+	r := &resolver[Traced[string]]{
+		opts:      opts,
+		flattener: f.flatten,
+		mapper: func(t *ssa.Const) (Traced[string], bool) {
+			s, ok := constToString(t)
+			return Traced[string]{Value: s}, ok
+		},
+		trace: func(v ssa.Value, user ssa.Instruction, res []Traced[string]) []Traced[string] {
+			o := newOrigin(v, user)
+			traced := make([]Traced[string], 0, len(res))
+			for _, t := range res {
+				traced = append(traced, Traced[string]{Value: t.Value, Path: concatPaths([]Origin{o}, t.Path)})
+			}
+			return traced
+		},
+	}
+	return r.resolve(v, resolveState{})
+}
+
+func concatPaths(x, y []Origin) []Origin {
+	return append(slices.Clip(x), y...)
+}
+
+// newOrigin returns the origin of v used by user.
+func newOrigin(v ssa.Value, user ssa.Instruction) Origin {
+	var fn *ssa.Function
+	switch v := v.(type) {
+	case ssa.Instruction:
+		fn = v.Parent()
+	case *ssa.Parameter:
+		fn = v.Parent()
+	case *ssa.FreeVar:
+		fn = v.Parent()
+	case *ssa.Function:
+		fn = v
+	}
+	var userPos token.Pos
+	if user != nil {
+		if fn == nil {
+			fn = user.Parent()
+		}
+		userPos = user.Pos()
+	}
+
+	o := Origin{Value: v, Pos: NewPos(fn, v.Pos(), userPos)}
+	if c, ok := v.(*ssa.Const); ok && user != nil {
+		if lit := findLiteral(user.Parent(), c, userPos); lit.IsValid() {
+			o.Pos = NewPos(fn, lit)
+		}
+	}
+	if phi, ok := user.(*ssa.Phi); ok {
+		if i := slices.Index(phi.Edges, v); i >= 0 && i < len(phi.Block().Preds) {
+			o.Pred = phi.Block().Preds[i]
+		}
+	}
+	return o
+}
+
+// findLiteral returns the position of the literal of c in the syntax of fn closest to pos, or token.NoPos if not found.
+func findLiteral(fn *ssa.Function, c *ssa.Const, pos token.Pos) token.Pos {
+	if fn == nil || fn.Syntax() == nil || c.Value == nil {
+		return token.NoPos
+	}
+	res := token.NoPos
+	dist := func(p token.Pos) int {
+		if p < pos {
+			return int(pos - p)
+		}
+		return int(p - pos)
+	}
+	ast.Inspect(fn.Syntax(), func(n ast.Node) bool {
+		bl, ok := n.(*ast.BasicLit)
+		if !ok {
+			return true
+		}
+		lit := constant.MakeFromLiteral(bl.Value, bl.Kind, 0)
+		if lit.Kind() == c.Value.Kind() && constant.Compare(lit, token.EQL, c.Value) {
+			if !res.IsValid() || dist(bl.Pos()) < dist(res) {
+				res = bl.Pos()
+			}
+		}
+		return true
+	})
+	return res
+}
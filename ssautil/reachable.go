@@ -0,0 +1,64 @@
+package ssautil
+
+import (
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ReachableCall is a call site matching a pattern that is transitively reachable from a root function.
+type ReachableCall struct {
+	Call    CallInfo
+	Site    ssa.CallInstruction
+	Pattern *Pattern
+	// Path is the chain of call sites from the root to the matching call site. The last element is the call site itself.
+	Path []*Posx
+}
+
+// FindReachableCalls reports every call site matching any of patterns that is reachable from root in g.
+// Each call site is reported once with the shortest call path that leads to it.
+func (g *CallGraph) FindReachableCalls(root *ssa.Function, patterns ...*Pattern) []*ReachableCall {
+	return g.findReachableCalls(root, patterns, false)
+}
+
+// Reaches reports whether root transitively calls anything matching any of patterns.
+func (g *CallGraph) Reaches(root *ssa.Function, patterns ...*Pattern) bool {
+	return len(g.findReachableCalls(root, patterns, true)) > 0
+}
+
+// findReachableCalls searches the call sites reachable from root breadth-first.
+// If first is set, it stops at the first matching call site.
+func (g *CallGraph) findReachableCalls(root *ssa.Function, patterns []*Pattern, first bool) []*ReachableCall {
+	res := make([]*ReachableCall, 0)
+	paths := map[*ssa.Function][]*Posx{root: {}}
+	queue := []*ssa.Function{root}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				site, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				cs := GetCallSite(site)
+				if i := slices.IndexFunc(patterns, func(p *Pattern) bool { return p.Match(cs) }); i > -1 {
+					path := append(slices.Clip(paths[fn]), NewPos(fn, site.Pos()))
+					res = append(res, &ReachableCall{Call: cs.CallInfo, Site: site, Pattern: patterns[i], Path: path})
+					if first {
+						return res
+					}
+				}
+			}
+		}
+
+		for _, e := range g.Callees(fn) {
+			if _, ok := paths[e.Callee]; !ok {
+				paths[e.Callee] = append(slices.Clip(paths[fn]), e.Pos)
+				queue = append(queue, e.Callee)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,277 @@
+package ssautil
+
+import (
+	"go/token"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// packageFunctions returns the functions declared in pkg, including methods and function literals.
+func packageFunctions(pkg *ssa.Package) []*ssa.Function {
+	funcs := make([]*ssa.Function, 0)
+	var addAnons func(fn *ssa.Function)
+	addAnons = func(fn *ssa.Function) {
+		funcs = append(funcs, fn)
+		for _, anon := range fn.AnonFuncs {
+			addAnons(anon)
+		}
+	}
+	for _, mem := range pkg.Members {
+		switch mem := mem.(type) {
+		case *ssa.Function:
+			addAnons(mem)
+		case *ssa.Type:
+			for _, t := range []types.Type{mem.Type(), types.NewPointer(mem.Type())} {
+				mset := pkg.Prog.MethodSets.MethodSet(t)
+				for i := 0; i < mset.Len(); i++ {
+					if fn := pkg.Prog.MethodValue(mset.At(i)); fn != nil && fn.Pkg == pkg && fn.Synthetic == "" {
+						addAnons(fn)
+					}
+				}
+			}
+		}
+	}
+	return funcs
+}
+
+// scopeFunctions returns the functions that may refer to an object of pkg.
+// These are the functions of pkg if the object is not exported, or of every package in the program otherwise.
+func scopeFunctions(pkg *ssa.Package, exported bool) []*ssa.Function {
+	if pkg == nil {
+		return nil
+	}
+	if !exported {
+		return packageFunctions(pkg)
+	}
+	funcs := make([]*ssa.Function, 0)
+	for _, p := range pkg.Prog.AllPackages() {
+		funcs = append(funcs, packageFunctions(p)...)
+	}
+	return funcs
+}
+
+// callSites returns the call sites that statically call fn, either directly or through a closure of fn.
+func callSites(fn *ssa.Function) []ssa.CallInstruction {
+	return dataOf(fn.Prog).callSites()[fn]
+}
+
+// variableStores returns the stores to the variable at addr.
+// addr may be a global, a local variable or a variable captured by a closure, or a field of one of them.
+func variableStores(addr ssa.Value) ([]*ssa.Store, bool) {
+	return scanStores(addr, func(a ssa.Value) bool { return sameAddr(a, addr) })
+}
+
+// memoryStores returns the stores whose values v may read from memory,
+// if v is a load of a variable or a read of a struct field.
+func memoryStores(v ssa.Value) ([]*ssa.Store, bool) {
+	switch v := v.(type) {
+	case *ssa.UnOp:
+		if v.Op == token.MUL {
+			return loadedStores(v.X)
+		}
+	case *ssa.Field:
+		return structFieldStores(v.X, v.Field)
+	}
+	return nil, false
+}
+
+// loadedStores returns the stores whose values a load from addr may read.
+// For a field, these are the stores to the field and the stores of the field of the structs stored as a whole.
+func loadedStores(addr ssa.Value) ([]*ssa.Store, bool) {
+	fa, ok := addr.(*ssa.FieldAddr)
+	if !ok {
+		return variableStores(addr)
+	}
+	return fieldStores(fa.X, fa.Field)
+}
+
+// fieldStores returns the stores of the values that the field of the struct pointed to by base may hold.
+func fieldStores(base ssa.Value, field int) ([]*ssa.Store, bool) {
+	res, _ := scanStores(base, func(a ssa.Value) bool {
+		fa, ok := a.(*ssa.FieldAddr)
+		return ok && fa.Field == field && sameAddr(fa.X, base)
+	})
+	if whole, ok := loadedStores(base); ok {
+		for _, store := range whole {
+			if ss, ok := structFieldStores(store.Val, field); ok {
+				res = append(res, ss...)
+			}
+		}
+	}
+	return res, len(res) > 0
+}
+
+// structFieldStores returns the stores of the values that the field of the struct value v may hold.
+func structFieldStores(v ssa.Value, field int) ([]*ssa.Store, bool) {
+	if load, ok := v.(*ssa.UnOp); ok && load.Op == token.MUL {
+		return fieldStores(load.X, field)
+	}
+	return nil, false
+}
+
+// scanStores returns the stores to an address satisfying match
+// in the functions that may refer to the variable that addr is derived from.
+// It fails if addr is derived from a parameter, since the callers may also store through the pointer,
+// or if the address of the variable escapes, such as by passing it to a call.
+func scanStores(addr ssa.Value, match func(a ssa.Value) bool) ([]*ssa.Store, bool) {
+	var funcs []*ssa.Function
+	root := addrRoot(addr)
+	switch a := root.(type) {
+	case *ssa.Global:
+		funcs = scopeFunctions(a.Package(), a.Object() != nil && a.Object().Exported())
+	case *ssa.Alloc, *ssa.FreeVar:
+		fn := a.Parent()
+		for fn.Parent() != nil {
+			fn = fn.Parent() // a captured variable may be stored by the enclosing function and any closure
+		}
+		var addAnons func(fn *ssa.Function)
+		addAnons = func(fn *ssa.Function) {
+			funcs = append(funcs, fn)
+			for _, anon := range fn.AnonFuncs {
+				addAnons(anon)
+			}
+		}
+		addAnons(fn)
+	default:
+		return nil, false
+	}
+
+	res := make([]*ssa.Store, 0)
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if store, ok := instr.(*ssa.Store); ok && match(store.Addr) {
+					res = append(res, store)
+				}
+				if escapes(instr, root) {
+					return nil, false
+				}
+			}
+		}
+	}
+	return res, len(res) > 0
+}
+
+// escapes reports whether instr uses an address derived from the variable root otherwise than
+// to load from it or store to it, so that the variable may be stored through a pointer elsewhere.
+func escapes(instr ssa.Instruction, root ssa.Value) bool {
+	var buf [10]*ssa.Value
+	for _, op := range instr.Operands(buf[:0]) {
+		if *op == nil || !derivesFrom(*op, root) {
+			continue
+		}
+		switch instr := instr.(type) {
+		case *ssa.Store:
+			if instr.Addr == *op && instr.Val != *op {
+				continue
+			}
+		case *ssa.UnOp:
+			if instr.Op == token.MUL {
+				continue
+			}
+		case *ssa.MakeClosure:
+			if instr.Fn != *op {
+				continue // the closures are scanned
+			}
+		case *ssa.FieldAddr, *ssa.DebugRef:
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// derivesFrom reports whether addr is the variable root or the address of a field of it.
+func derivesFrom(addr ssa.Value, root ssa.Value) bool {
+	for {
+		fa, ok := addr.(*ssa.FieldAddr)
+		if !ok {
+			break
+		}
+		addr = fa.X
+	}
+	switch addr.(type) {
+	case *ssa.Alloc, *ssa.FreeVar, *ssa.Global:
+		return sameAddr(addr, root)
+	}
+	return false
+}
+
+// addrRoot returns the variable that addr is derived from by field selections and pointer loads.
+func addrRoot(addr ssa.Value) ssa.Value {
+	switch a := addr.(type) {
+	case *ssa.FieldAddr:
+		return addrRoot(a.X)
+	case *ssa.UnOp:
+		if a.Op == token.MUL {
+			return addrRoot(a.X)
+		}
+	}
+	return addr
+}
+
+// sameAddr reports whether a and b may refer to the same variable.
+// Free variables of closures refer to the variables they are bound to,
+// fields are the same if their structs are, and loads of the same pointer variable are the same pointer.
+func sameAddr(a, b ssa.Value) bool {
+	if a == b {
+		return true
+	}
+	if fv, ok := a.(*ssa.FreeVar); ok {
+		return slices.ContainsFunc(freeVarBindings(fv), func(v ssa.Value) bool { return sameAddr(v, b) })
+	}
+	if fv, ok := b.(*ssa.FreeVar); ok {
+		return slices.ContainsFunc(freeVarBindings(fv), func(v ssa.Value) bool { return sameAddr(a, v) })
+	}
+	switch a := a.(type) {
+	case *ssa.FieldAddr:
+		b, ok := b.(*ssa.FieldAddr)
+		return ok && a.Field == b.Field && sameAddr(a.X, b.X)
+	case *ssa.UnOp:
+		b, ok := b.(*ssa.UnOp)
+		return ok && a.Op == token.MUL && b.Op == token.MUL && sameAddr(a.X, b.X)
+	}
+	return false
+}
+
+// freeVarBindings returns the values bound to fv by the MakeClosure instructions of its function.
+func freeVarBindings(fv *ssa.FreeVar) []ssa.Value {
+	fn := fv.Parent()
+	i := slices.Index(fn.FreeVars, fv)
+	if i < 0 || fn.Parent() == nil {
+		return nil
+	}
+	res := make([]ssa.Value, 0)
+	for _, b := range fn.Parent().Blocks {
+		for _, instr := range b.Instrs {
+			if mc, ok := instr.(*ssa.MakeClosure); ok && mc.Fn == fn {
+				res = append(res, mc.Bindings[i])
+			}
+		}
+	}
+	return res
+}
+
+// returnInstrs returns the return instructions of the static callee of call, whose idx-th results are the results of call.
+func returnInstrs(call *ssa.CallCommon, idx int) ([]*ssa.Return, bool) {
+	var fn *ssa.Function
+	switch callee := call.Value.(type) {
+	case *ssa.Function:
+		fn = callee
+	case *ssa.MakeClosure:
+		fn, _ = callee.Fn.(*ssa.Function)
+	}
+	if fn == nil || call.IsInvoke() || len(fn.Blocks) == 0 {
+		return nil, false
+	}
+
+	res := make([]*ssa.Return, 0)
+	for _, b := range fn.Blocks {
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok && idx < len(ret.Results) {
+			res = append(res, ret)
+		}
+	}
+	return res, len(res) > 0
+}
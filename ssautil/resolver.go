@@ -0,0 +1,211 @@
+package ssautil
+
+import (
+	"slices"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// ResolveOptions configures how far values are followed to resolve them to constants.
+type ResolveOptions struct {
+	// MaxDepth is the maximum number of values followed in a chain from the value to a constant.
+	MaxDepth int
+	// MaxCallDepth is the maximum number of calls followed in a chain, either from a parameter to the arguments
+	// of the call sites of its function or from the result of a call to the returns of its static callee.
+	// Zero disables interprocedural resolution.
+	MaxCallDepth int
+	// Models are the models of the results of library calls in ValueToStrings, ValueToAbstractStrings and ValueToInts.
+	// Nil means the models returned by DefaultModels.
+	Models *Models
+	// Strict makes the resolution fail if any of the values merged into a value is not resolved,
+	// such as an edge of a Phi, a value stored to a variable or an argument of a call site of a function.
+	// Otherwise the values not resolved are dropped, and the resolution succeeds if any of them is resolved.
+	// ValueToAbstractStrings makes them holes instead in either case.
+	Strict bool
+}
+
+// DefaultResolveOptions returns the options of the functions that take no options, such as ValueToStrings.
+// They follow values to a depth of 10 within the function of the value and do not follow calls.
+// Use the WithOptions variants with a MaxCallDepth to resolve values across parameters and returns.
+func DefaultResolveOptions() ResolveOptions {
+	return ResolveOptions{MaxDepth: 10}
+}
+
+// withMaxDepth returns the options of the WithMaxDepth variants.
+func withMaxDepth(maxDepth int) ResolveOptions {
+	opts := DefaultResolveOptions()
+	opts.MaxDepth = maxDepth
+	return opts
+}
+
+type resolver[T any] struct {
+	opts      ResolveOptions
+	flattener ToConstsFunc[T]
+	mapper    func(t *ssa.Const) (T, bool)
+	// trace, if not nil, annotates the results of each value followed with the value and the instruction using it.
+	trace func(v ssa.Value, user ssa.Instruction, res []T) []T
+	// guard, if not nil, annotates the results of each edge of a Phi with the edge.
+	guard func(phi *ssa.Phi, edge int, res []T) []T
+	// unknown, if not nil, is the result of a merged value that is not resolved when the others are.
+	unknown func() T
+	// dropped reports whether any of the merged values was not resolved and dropped.
+	dropped bool
+}
+
+// resolveState is the context in which a value is resolved.
+type resolveState struct {
+	depth int
+	calls int
+	// stack is the call string of the call sites whose callees were entered from their results, innermost last.
+	// A parameter of the innermost callee is resolved to the argument of the call site only.
+	stack []ssa.CallInstruction
+	// user is the instruction that uses the value being resolved, nil for the value resolution starts from.
+	user ssa.Instruction
+}
+
+// use is a value used by an instruction.
+type use struct {
+	val  ssa.Value
+	user ssa.Instruction
+}
+
+func (r *resolver[T]) resolve(v ssa.Value, st resolveState) ([]T, bool) {
+	res, ok := r.resolveValue(v, st)
+	if ok && r.trace != nil {
+		res = r.trace(v, st.user, res)
+	}
+	return res, ok
+}
+
+func (r *resolver[T]) resolveValue(v ssa.Value, st resolveState) ([]T, bool) {
+	if st.depth > r.opts.MaxDepth {
+		return []T{}, false
+	}
+	st.depth++
+	user := st
+	user.user, _ = v.(ssa.Instruction)
+	next := func(v ssa.Value) ([]T, bool) {
+		return r.resolve(v, user)
+	}
+	switch t := v.(type) {
+	case *ssa.Const:
+		if t, ok := r.mapper(t); ok {
+			return []T{t}, true
+		}
+	case *ssa.Phi:
+		if cs, ok := r.flattener(t, next); ok {
+			return cs, true
+		}
+		if slices.ContainsFunc(t.Edges, func(e ssa.Value) bool { return dependsOn(e, t, map[ssa.Value]bool{}) }) {
+			return []T{}, false // a value updated in a loop, such as an induction variable
+		}
+		return r.merge(len(t.Edges), func(i int) ([]T, bool) {
+			res, ok := next(t.Edges[i])
+			if ok && r.guard != nil {
+				res = r.guard(t, i, res)
+			}
+			return res, ok
+		})
+	default:
+		if cs, ok := r.flattener(t, next); ok {
+			return cs, true
+		}
+		// loads of globals, local variables and struct fields are resolved to the values stored to them
+		if stores, ok := memoryStores(t); ok {
+			uses := make([]use, 0, len(stores))
+			for _, store := range stores {
+				uses = append(uses, use{val: store.Val, user: store})
+			}
+			return r.resolveUses(uses, st)
+		}
+		if fv, ok := t.(*ssa.FreeVar); ok {
+			bindings := freeVarBindings(fv)
+			return r.merge(len(bindings), func(i int) ([]T, bool) { return next(bindings[i]) })
+		}
+		return r.resolveCall(t, st)
+	}
+	return []T{}, false
+}
+
+// merge resolves n merged values with resolve and concatenates the results. It succeeds if any of them is resolved.
+// The values not resolved are unknown if r.unknown is not nil, or make it fail if opts.Strict, or are dropped otherwise.
+func (r *resolver[T]) merge(n int, resolve func(i int) ([]T, bool)) ([]T, bool) {
+	res := make([]T, 0)
+	ok, unresolved := false, false
+	for i := 0; i < n; i++ {
+		rs, rok := resolve(i)
+		if !rok {
+			if r.opts.Strict && r.unknown == nil {
+				return []T{}, false
+			}
+			unresolved = true
+			continue
+		}
+		res = append(res, rs...)
+		ok = true
+	}
+	if ok && unresolved {
+		if r.unknown != nil {
+			res = append(res, r.unknown())
+		} else {
+			r.dropped = true
+		}
+	}
+	return res, ok
+}
+
+// resolveUses resolves each of uses and merges the results.
+func (r *resolver[T]) resolveUses(uses []use, st resolveState) ([]T, bool) {
+	return r.merge(len(uses), func(i int) ([]T, bool) {
+		st.user = uses[i].user
+		return r.resolve(uses[i].val, st)
+	})
+}
+
+// resolveCall follows a parameter to the arguments of its call sites and the result of a call to the returns of its callee.
+func (r *resolver[T]) resolveCall(v ssa.Value, st resolveState) ([]T, bool) {
+	switch t := v.(type) {
+	case *ssa.Parameter:
+		fn := t.Parent()
+		i := slices.Index(fn.Params, t)
+		if n := len(st.stack); n > 0 && st.stack[n-1].Common().StaticCallee() == fn {
+			// return to the call site that the callee was entered from
+			site := st.stack[n-1]
+			st.stack = st.stack[:n-1]
+			st.user = site
+			return r.resolve(site.Common().Args[i], st)
+		}
+		if st.calls >= r.opts.MaxCallDepth {
+			return []T{}, false
+		}
+		st.calls++
+		uses := make([]use, 0)
+		for _, site := range callSites(fn) {
+			if i < len(site.Common().Args) {
+				uses = append(uses, use{val: site.Common().Args[i], user: site})
+			}
+		}
+		return r.resolveUses(uses, st)
+	case *ssa.Call:
+		return r.resolveReturns(t, 0, st)
+	case *ssa.Extract:
+		if call, ok := t.Tuple.(*ssa.Call); ok {
+			return r.resolveReturns(call, t.Index, st)
+		}
+	}
+	return []T{}, false
+}
+
+func (r *resolver[T]) resolveReturns(call *ssa.Call, idx int, st resolveState) ([]T, bool) {
+	rets, ok := returnInstrs(call.Common(), idx)
+	if !ok || st.calls >= r.opts.MaxCallDepth {
+		return []T{}, false
+	}
+	st.calls++
+	st.stack = append(slices.Clip(st.stack), call)
+	uses := make([]use, 0, len(rets))
+	for _, ret := range rets {
+		uses = append(uses, use{val: ret.Results[idx], user: ret})
+	}
+	return r.resolveUses(uses, st)
+}
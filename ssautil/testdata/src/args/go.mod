@@ -0,0 +1,3 @@
+module github.com/haijima/analysisutil/ssautil/testdata/src/args
+
+go 1.22.2
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+)
+
+func main() {
+}
+
+func query(db *sql.DB, id int) {
+	_, _ = db.Query("SELECT * FROM users WHERE id = ?", id)
+	_, _ = db.Query("DELETE FROM users WHERE id = ?", id)
+}
+
+func setenv(dir string) {
+	_ = os.Setenv("PATH", "/bin")
+	_ = os.Setenv("HOME", dir)
+}
+
+func repeat() {
+	_ = strings.Repeat("-", 10)
+}
@@ -9,6 +9,7 @@ func main() {
 	staticMethod(Foo{name: "foo"})
 	staticMethod2(Fizz{name: "fizz"})
 	dynamicMethod(Foo{name: "foo"})
+	dynamicMethod(&Fizz{name: "fizz"})
 	dynamicMethod2(B{})
 	builtinDynamicMethod(errors.New("error"))
 	staticFunc()
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+)
+
+type Shape interface {
+	Area() int
+}
+
+type Square struct {
+	n int
+}
+
+func (s Square) Area() int { return s.n * s.n }
+
+type Rect struct {
+	w, h int
+}
+
+func (r *Rect) Area() int { return r.w * r.h }
+
+type Circle struct {
+	r int
+}
+
+func (c Circle) Area() int { return 3 * c.r * c.r }
+
+func main() {
+	total := sum([]Shape{Square{n: 2}, &Rect{w: 1, h: 2}})
+	fmt.Println(total, Circle{r: 1}.Area(), apply(double))
+}
+
+func sum(shapes []Shape) int {
+	total := 0
+	for _, s := range shapes {
+		total += s.Area()
+	}
+	return total
+}
+
+func double(n int) int {
+	return n * 2
+}
+
+func apply(f func(int) int) int {
+	return f(1)
+}
+
+func unused() Shape {
+	type labeled struct {
+		Shape
+	}
+	return labeled{Circle{r: 2}}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func main() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go work(&wg)
+	go wg.Wait()
+	defer wg.Wait()
+	defer func() {
+		fmt.Println("deferred")
+	}()
+	f := func() {
+		fmt.Println("escaped")
+	}
+	run(f)
+	wg.Wait()
+}
+
+func work(wg *sync.WaitGroup) {
+	defer wg.Done()
+	fmt.Println("work")
+}
+
+func run(f func()) {
+	f()
+}
@@ -0,0 +1,39 @@
+package main
+
+import "slices"
+
+type Box[T any] struct {
+	v T
+}
+
+func (b Box[T]) Get() T {
+	return b.v
+}
+
+type Getter[T any] interface {
+	Get() T
+}
+
+func Map[K comparable, V any](m map[K]V) []V {
+	res := make([]V, 0, len(m))
+	for _, v := range m {
+		res = append(res, v)
+	}
+	return res
+}
+
+func main() {
+	calls()
+}
+
+func calls() {
+	_ = Map(map[int]string{1: "one"})
+	_ = Map(map[string]int{"one": 1})
+	_ = slices.Contains([]string{"a"}, "a")
+	_ = Box[int]{v: 1}.Get()
+	get(Box[string]{v: "s"})
+}
+
+func get(g Getter[string]) {
+	_ = g.Get()
+}
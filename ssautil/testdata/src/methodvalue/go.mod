@@ -0,0 +1,3 @@
+module github.com/haijima/analysisutil/ssautil/testdata/src/methodvalue
+
+go 1.22.2
@@ -0,0 +1,43 @@
+package main
+
+type T struct {
+	name string
+}
+
+func (t T) M(s string) string {
+	return t.name + s
+}
+
+func (t *T) P(s string) string {
+	return t.name + s
+}
+
+type I interface {
+	M(s string) string
+}
+
+type J interface {
+	I
+}
+
+func main() {
+	t := T{name: "t"}
+	calls(t, &t, t, t)
+}
+
+func calls(t T, pt *T, i I, j J) {
+	_ = t.M("a")
+	f := t.M
+	_ = f("b")
+	_ = T.M(t, "c")
+	_ = (*T).P(pt, "d")
+	g := i.M
+	_ = g("e")
+	_ = I.M(i, "f")
+	_ = j.M("g")
+	apply(pt.P)
+}
+
+func apply(f func(string) string) {
+	_ = f("h")
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/haijima/analysisutil/ssautil/testdata/src/program/sub"
+)
+
+func main() {
+	_ = sub.Query("users")
+}
@@ -0,0 +1,7 @@
+package sub
+
+import "strings"
+
+func Query(table string) string {
+	return "SELECT * FROM " + strings.ToUpper(table)
+}
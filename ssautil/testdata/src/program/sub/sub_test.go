@@ -0,0 +1,9 @@
+package sub
+
+import "testing"
+
+func TestQuery(t *testing.T) {
+	if Query("users") == "" {
+		t.Fail()
+	}
+}
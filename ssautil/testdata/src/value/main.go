@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const selectUsers = "SELECT * FROM users"
+
+var deleteUsers = "DELETE FROM users"
+
+var countUsers = "SELECT COUNT(*) FROM users"
+
+var allowedTables = []string{"users", "posts"}
+
+var routes = map[string]string{
+	"/users": "users",
+	"/posts": "posts",
+}
+
+var queries = struct {
+	Insert string
+	Update string
+}{
+	Insert: "INSERT INTO users",
+	Update: "UPDATE users",
+}
+
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+func main() {
+}
+
+func constant(db *sql.DB) {
+	_, _ = db.Query(selectUsers)
+}
+
+func global(db *sql.DB) {
+	_, _ = db.Query(deleteUsers)
+}
+
+func globalField(db *sql.DB) {
+	_, _ = db.Query(queries.Insert)
+	_, _ = db.Query(queries.Update + " SET name = ?")
+}
+
+func localField(db *sql.DB) {
+	q := Query{SQL: "SELECT name FROM users"}
+	_, _ = db.Query(q.SQL)
+}
+
+func pointerField(db *sql.DB) {
+	q := &Query{SQL: "SELECT id FROM users"}
+	_, _ = db.Query(q.SQL)
+}
+
+func reassignedField(db *sql.DB, admin bool) {
+	q := &Query{SQL: "SELECT * FROM members"}
+	if admin {
+		q.SQL = "SELECT * FROM admins"
+	}
+	_, _ = db.Query(q.SQL)
+}
+
+func paramField(db *sql.DB, q *Query, reset bool) {
+	if reset {
+		q.SQL = "SELECT * FROM visitors"
+	}
+	_, _ = db.Query(q.SQL)
+}
+
+func setQuery(p *string) {
+	*p = "SELECT * FROM admins"
+}
+
+func escapedLocal(db *sql.DB) {
+	query := "SELECT * FROM users"
+	setQuery(&query)
+	_, _ = db.Query(query)
+}
+
+func escapedGlobal(db *sql.DB) {
+	setQuery(&countUsers)
+	_, _ = db.Query(countUsers)
+}
+
+func capturedVar(db *sql.DB) {
+	query := "SELECT 1"
+	func() {
+		query = "SELECT 2"
+	}()
+	_, _ = db.Query(query)
+}
+
+func buildQuery(table string) string {
+	return "SELECT * FROM " + table
+}
+
+func buildLimitedQuery(table string) string {
+	return buildQuery(table) + " LIMIT 1"
+}
+
+func helper(db *sql.DB) {
+	_, _ = db.Query(buildQuery("users"))
+	_, _ = db.Query(buildLimitedQuery("posts"))
+}
+
+func queryParam(db *sql.DB, query string) {
+	_, _ = db.Query(query)
+}
+
+func callers(db *sql.DB) {
+	queryParam(db, "SELECT 3")
+	queryParam(db, "SELECT 4")
+}
+
+func twoResults() (string, error) {
+	return "SELECT 5", nil
+}
+
+func tuple(db *sql.DB) {
+	q, _ := twoResults()
+	_, _ = db.Query(q)
+}
+
+func abstract(db *sql.DB, table string, column string) {
+	_, _ = db.Query("SELECT * FROM " + table + " WHERE " + column + " = ?")
+}
+
+func sprintf(db *sql.DB) {
+	_, _ = db.Query(fmt.Sprintf("SELECT * FROM %s WHERE id = ?",
+		"users")) // table
+}
+
+func branches(db *sql.DB, admin bool, n int) {
+	query := "SELECT * FROM users"
+	if admin {
+		query = "SELECT * FROM admins"
+	} else if n > 10 {
+		query = "SELECT * FROM users LIMIT 10"
+	}
+	_, _ = db.Query(query)
+}
+
+func branchesConcat(db *sql.DB, desc bool, table string) {
+	order := ""
+	if desc {
+		order = " DESC"
+	}
+	_, _ = db.Query(fmt.Sprintf("SELECT * FROM %s", "users") + " ORDER BY id" + order)
+	if table == "" {
+		table = "users"
+	}
+	_, _ = db.Exec("DELETE FROM " + table)
+}
+
+func limit(db *sql.DB, n int) {
+	_, _ = db.Query(fmt.Sprintf("SELECT * FROM users LIMIT %d", n))
+}
+
+func limitBranch(db *sql.DB, n int, paged bool) {
+	size := 10
+	if paged {
+		size = n
+	}
+	_, _ = db.Query(fmt.Sprintf("SELECT * FROM users LIMIT %d", size))
+}
+
+func formats(n int, table string) {
+	_ = fmt.Sprintf("SELECT * FROM %s WHERE id = %d", "users", 1)
+	_ = fmt.Sprintf("%[2]s %[1]q", "users", "SELECT")
+	_ = fmt.Sprintf("%5.2f|%-*d|%x|%t", 3.14159, 4, 7, 255, true)
+	_ = fmt.Sprintf("LIMIT %d", n)
+	_ = fmt.Sprintf("FROM %s", table)
+	_ = fmt.Sprint("SELECT ", 1, 2)
+	_ = fmt.Sprintln("SELECT", 1)
+	_ = fmt.Errorf("no rows in %s", "users")
+	_ = fmt.Appendf(nil, "%d%%", 50)
+	_ = fmt.Sprintf("%d %d", 1)
+}
+
+func tableName(kind int) string {
+	return <-make(chan string) // not resolvable without a model
+}
+
+func models(table string) {
+	_ = strings.ToUpper("select * from " + table)
+	_ = strconv.Itoa(len("abc") * 2)
+	_ = path.Join("a", "b", "../c")
+	_ = strings.ReplaceAll("a-b-c", "-", "_")
+	_ = tableName(2)
+}
+
+func builder(db *sql.DB, desc bool, columns []string) {
+	var b strings.Builder
+	b.WriteString("SELECT * FROM users")
+	b.WriteByte(' ')
+	if desc {
+		b.WriteString("ORDER BY id DESC")
+	} else {
+		fmt.Fprintf(&b, "ORDER BY %s", "id")
+	}
+	_, _ = db.Query(b.String())
+}
+
+func builderLoop(db *sql.DB, n int) {
+	buf := &bytes.Buffer{}
+	buf.Write([]byte("SELECT 1"))
+	for i := 0; i < n; i++ {
+		buf.WriteString(", 1")
+	}
+	_, _ = db.Query(buf.String())
+}
+
+func builderCounted(db *sql.DB, n int) {
+	var b strings.Builder
+	b.WriteString("SELECT 1")
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i
+	}
+	_ = total
+	_, _ = db.Query(b.String())
+}
+
+func builderByte(db *sql.DB, c byte) {
+	var b strings.Builder
+	b.WriteString("SELECT ")
+	b.WriteByte(c)
+	_, _ = db.Query(b.String())
+}
+
+func builderUnknown(db *sql.DB, table string) {
+	var b strings.Builder
+	b.WriteString("SELECT * FROM ")
+	b.WriteString(table)
+	b.WriteRune(';')
+	_, _ = db.Query(b.String())
+}
+
+func size(n int64) {}
+
+func ints(n int, b uint8, i8 int8, u uint64, c bool) {
+	size(int64(17 % 5))
+	size(int64(0x0f&0x3c | 0x100 ^ 0x1))
+	size(int64(1 << 10 >> 2 &^ 0x80))
+	size(int64(-n & 0xff))
+	size(int64(b + 1))
+	size(int64(uint8(300 + n*0)))
+	size(int64(i8) * 2)
+	size(int64(n % 10))
+	for i := 0; i < 100; i++ {
+		size(int64(i))
+	}
+	for j := 10; j >= 0; j -= 3 {
+		size(int64(j))
+	}
+	size(int64(b >> 4))
+	size(int64(^b))
+	size(int64(n))
+	size(int64(u))
+	size(int64(b) >> u)
+	s := int64(-1)
+	if c {
+		s = -2
+	}
+	size(int64(n) >> s)
+}
+
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+)
+
+func timeout(d time.Duration) {}
+
+func ratio(f float32) {}
+
+func enabled(b bool) {}
+
+func mask(u uint64) {}
+
+func level(l Level) {}
+
+func impedance(c complex128) {}
+
+func num(i int) int {
+	return i
+}
+
+func typedConsts(verbose bool) {
+	timeout(5 * time.Second)
+	timeout(time.Duration(num(3)) * time.Millisecond)
+	ratio(float32(num(1)) / 3)
+	enabled(num(2)%2 == 0)
+	enabled(num(1) > 0)
+	mask(^uint64(num(0)))
+	level(Info)
+	l := Debug
+	if verbose {
+		l = Level(num(3))
+	}
+	level(l)
+	c := complex(float64(num(1)), 2)
+	impedance(c)
+	impedance(complex(imag(c), real(c)))
+}
+
+func tables(names []string) {}
+
+func ports(ps []int) {}
+
+func levels(ls []Level) {}
+
+func router(m map[string]string) {}
+
+func allowTable() {
+	allowedTables[1] = "comments"
+}
+
+func addRoute() {
+	routes["/admin"] = "admin"
+}
+
+func collections(verbose bool, names []string) {
+	tables(allowedTables)
+	tables(append(allowedTables[1:], "comments", selectUsers[14:]))
+	tables(append([]string{"members"}, names...))
+	overwritten := []string{"a", "b"}
+	overwritten[0] = "z"
+	tables(overwritten)
+	ports([]int{80, 443})
+	arr := [4]int{8080, 2: 8443}
+	ports(arr[:3])
+	levels([]Level{Debug, Warn})
+	router(routes)
+	m := map[string]string{"/": "index"}
+	if verbose {
+		m = map[string]string{"/": "index", "/debug": "debug"}
+	}
+	router(m)
+	merged := map[string]string{"/": "index"}
+	if verbose {
+		merged = map[string]string{}
+	}
+	merged["/debug"] = "debug"
+	router(merged)
+	optional := map[string]string{"/": "index"}
+	if verbose {
+		optional["/x"] = "x"
+	}
+	router(optional)
+	replaced := map[string]string{"a": "1"}
+	replaced["a"] = "2"
+	router(replaced)
+	var partial [2]string
+	partial[0] = "a"
+	if verbose {
+		partial[1] = "b"
+	}
+	tables(partial[:])
+	grown := []string{"a"}
+	for i := 0; i < len(names); i++ {
+		grown = append(grown, "b")
+	}
+	tables(grown)
+	_ = strings.Join(allowedTables, ", ")
+	_ = strings.Join([]string{"id", "name", strings.ToUpper("email")}, ",")
+}
@@ -1,13 +1,9 @@
 package ssautil
 
 import (
-	"go/ast"
 	"go/constant"
 	"go/token"
-	"regexp"
-	"slices"
 	"strconv"
-	"strings"
 
 	"golang.org/x/tools/go/ssa"
 )
@@ -15,46 +11,19 @@ import (
 type ToConstsFunc[T any] func(v ssa.Value, next func(v ssa.Value) ([]T, bool)) ([]T, bool)
 
 func ValueToConsts[T any](v ssa.Value, flattener ToConstsFunc[T], mapper func(t *ssa.Const) (T, bool)) ([]T, bool) {
-	return ValueToConstsWithMaxDepth[T](v, 10, flattener, mapper)
+	return ValueToConstsWithOptions[T](v, DefaultResolveOptions(), flattener, mapper)
 }
 
 func ValueToConstsWithMaxDepth[T any](v ssa.Value, maxDepth int, flattener ToConstsFunc[T], mapper func(t *ssa.Const) (T, bool)) ([]T, bool) {
-	return valueToConsts[T](v, 0, maxDepth, flattener, mapper)
+	return ValueToConstsWithOptions[T](v, withMaxDepth(maxDepth), flattener, mapper)
 }
 
-func valueToConsts[T any](v ssa.Value, depth, maxDepth int, flattener ToConstsFunc[T], mapper func(t *ssa.Const) (T, bool)) ([]T, bool) {
-	if depth > maxDepth {
-		return []T{}, false
-	}
-	depth++
-	switch t := v.(type) {
-	case *ssa.Const:
-		if t, ok := mapper(t); ok {
-			return []T{t}, true
-		}
-	case *ssa.Phi:
-		return phiToConsts[T](t, depth, maxDepth, flattener, mapper)
-	default:
-		if cs, ok := flattener(t, func(v ssa.Value) ([]T, bool) {
-			return valueToConsts[T](v, depth, maxDepth, flattener, mapper)
-		}); ok {
-			return cs, true
-		}
-	}
-	return []T{}, false
-}
-
-func phiToConsts[T any](t *ssa.Phi, depth, maxDepth int, flattener ToConstsFunc[T], mapper func(t *ssa.Const) (T, bool)) ([]T, bool) {
-	res := make([]T, 0, len(t.Edges))
-	for _, edge := range t.Edges {
-		if c, ok := valueToConsts(edge, depth, maxDepth, flattener, mapper); ok {
-			res = slices.Concat(res, c)
-		} else {
-			//	return []T{}, false
-		}
-	}
-	return res, len(res) > 0
-	//return valueToConsts[T](t.Edges[0], depth, maxDepth, flattener, mapper)
+// ValueToConstsWithOptions resolves v to constants with flattener and mapper.
+// Values that flattener does not resolve are followed through loads of variables and struct fields, free variables,
+// and as configured by opts, through parameters to the arguments of their call sites and through calls to their returns.
+func ValueToConstsWithOptions[T any](v ssa.Value, opts ResolveOptions, flattener ToConstsFunc[T], mapper func(t *ssa.Const) (T, bool)) ([]T, bool) {
+	r := &resolver[T]{opts: opts, flattener: flattener, mapper: mapper}
+	return r.resolve(v, resolveState{})
 }
 
 func ValueToInts(v ssa.Value) ([]int, bool) {
@@ -62,9 +31,25 @@ func ValueToInts(v ssa.Value) ([]int, bool) {
 }
 
 func ValueToIntsWithMaxDepth(v ssa.Value, maxDepth int) ([]int, bool) {
-	return valueToConsts[int](v, 0, maxDepth,
-		func(v ssa.Value, next func(v ssa.Value) ([]int, bool)) ([]int, bool) {
+	return ValueToIntsWithOptions(v, withMaxDepth(maxDepth))
+}
+
+func ValueToIntsWithOptions(v ssa.Value, opts ResolveOptions) ([]int, bool) {
+	return newIntResolver(opts).resolve(v, resolveState{})
+}
+
+// newIntResolver returns the resolver of ValueToIntsWithOptions.
+func newIntResolver(opts ResolveOptions) *resolver[int] {
+	return &resolver[int]{
+		opts: opts,
+		flattener: func(v ssa.Value, next func(v ssa.Value) ([]int, bool)) ([]int, bool) {
 			switch t := v.(type) {
+			case *ssa.Call:
+				ctx := &ModelContext{call: t, opts: opts, ints: next, hole: unknownHole,
+					strs: func(v ssa.Value) ([]AbstractString, bool) {
+						return ValueToAbstractStringsWithOptions(v, crossOptions(opts))
+					}}
+				return modelsOf(opts).intsOf(GetCallInfo(t.Common()), ctx)
 			case *ssa.BinOp:
 				x, xok := next(t.X)
 				y, yok := next(t.Y)
@@ -72,25 +57,25 @@ func ValueToIntsWithMaxDepth(v ssa.Value, maxDepth int) ([]int, bool) {
 					res := make([]int, 0, len(x)*len(y))
 					for _, xx := range x {
 						for _, yy := range y {
-							switch t.Op {
-							case token.ADD:
-								res = append(res, xx+yy)
-							case token.SUB:
-								res = append(res, xx-yy)
-							case token.MUL:
-								res = append(res, xx*yy)
-							case token.QUO:
-								if yy != 0 {
-									res = append(res, xx/yy)
-								}
+							if r, ok := intBinOp(t.Op, xx, yy, t.Type()); ok {
+								res = append(res, r)
 							}
 						}
 					}
-					return res, true
+					return res, len(res) > 0
+				}
+			case *ssa.UnOp:
+				if t.Op == token.SUB || t.Op == token.XOR {
+					return mapInts(t.X, next, func(x int) (int, bool) { return intUnOp(t.Op, x, t.Type()) })
 				}
+			case *ssa.Convert:
+				return mapInts(t.X, next, func(x int) (int, bool) { return wrapInt(x, t.Type()) })
+			case *ssa.ChangeType:
+				return mapInts(t.X, next, func(x int) (int, bool) { return wrapInt(x, t.Type()) })
 			}
 			return []int{}, false
-		}, func(t *ssa.Const) (int, bool) {
+		},
+		mapper: func(t *ssa.Const) (int, bool) {
 			if t.Value != nil && t.Value.Kind() == constant.Int {
 				if s, err := Unquote(t.Value.ExactString()); err == nil {
 					if i, err := strconv.Atoi(s); err == nil {
@@ -99,7 +84,23 @@ func ValueToIntsWithMaxDepth(v ssa.Value, maxDepth int) ([]int, bool) {
 				}
 			}
 			return 0, false
-		})
+		},
+	}
+}
+
+// mapInts returns the results of fn on the possible values of x, resolved with next.
+func mapInts(x ssa.Value, next func(v ssa.Value) ([]int, bool), fn func(x int) (int, bool)) ([]int, bool) {
+	xs, ok := next(x)
+	if !ok {
+		return []int{}, false
+	}
+	res := make([]int, 0, len(xs))
+	for _, xx := range xs {
+		if r, ok := fn(xx); ok {
+			res = append(res, r)
+		}
+	}
+	return res, len(res) > 0
 }
 
 func ValueToStrings(v ssa.Value) ([]string, bool) {
@@ -107,152 +108,185 @@ func ValueToStrings(v ssa.Value) ([]string, bool) {
 }
 
 func ValueToStringsWithMaxDepth(v ssa.Value, maxDepth int) ([]string, bool) {
-	return valueToConsts[string](v, 0, maxDepth,
-		// flattener
-		func(v ssa.Value, next func(v ssa.Value) ([]string, bool)) ([]string, bool) {
-			switch t := v.(type) {
-			case *ssa.BinOp:
-				return binOpToStrings(t, next)
-			case *ssa.Call:
-				c := GetCallInfo(t.Common())
-				if c.Match("fmt.Sprintf") {
-					return fmtSprintfToStrings(t, next)
-				} else if c.Match("strings.Join") {
-					return stringsJoinToStrings(t, next)
-				}
-			case *ssa.Slice:
-				// e.g.
-				// s := "hello"
-				// s[:len(s)-1]
-				s, ok := next(t.X)
-				if ok {
-					res := make([]string, 0, len(s))
-					for _, ss := range s {
-						l, lok := []int{0}, true
-						h, hok := []int{len(ss)}, true
-						if t.Low != nil {
-							l, lok = stringIndex(t.Low, t.X, len(ss), maxDepth)
-						}
-						if t.High != nil {
-							h, hok = stringIndex(t.High, t.X, len(ss), maxDepth)
-						}
-						if lok && hok {
-							for _, ll := range l {
-								for _, hh := range h {
-									res = append(res, ss[ll:hh])
-								}
-							}
-						}
-					}
-					return res, len(res) > 0
-				}
-			}
-			return []string{}, false
-		},
-		// mapper
-		func(t *ssa.Const) (string, bool) {
-			if t.Value != nil && t.Value.Kind() == constant.String {
-				if s, err := Unquote(t.Value.ExactString()); err == nil {
-					return s, true
-				}
-			}
-			return "", false
-		},
-	)
+	return ValueToStringsWithOptions(v, withMaxDepth(maxDepth))
 }
 
-func binOpToStrings(t *ssa.BinOp, fn func(v ssa.Value) ([]string, bool)) ([]string, bool) {
-	x, xok := fn(t.X)
-	y, yok := fn(t.Y)
-	if xok && yok && len(x) > 0 && len(y) > 0 && t.Op == token.ADD {
-		res := make([]string, 0, len(x)*len(y))
+func ValueToStringsWithOptions(v ssa.Value, opts ResolveOptions) ([]string, bool) {
+	f := &stringFlattener[string]{
+		opts:   opts,
+		str:    func(s string) string { return s },
+		derive: func(s string, _ ...string) string { return s },
+	}
+	return ValueToConstsWithOptions[string](v, opts, f.flatten, constToString)
+}
+
+func constToString(t *ssa.Const) (string, bool) {
+	if t.Value != nil && t.Value.Kind() == constant.String {
+		if s, err := Unquote(t.Value.ExactString()); err == nil {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// stringFlattener is the flattener of the string values in ValueToStrings, ValueToStringsTraced and
+// ValueToStringsGuarded, whose results of type T are made of strings.
+type stringFlattener[T any] struct {
+	opts ResolveOptions
+	// str returns the string of a result.
+	str func(t T) string
+	// derive returns the result of the string s derived from the results from, such as the operands of a concatenation.
+	derive func(s string, from ...T) T
+	// hole is the hole of the unknown operands of a modeled call. Nil means fmtPlaceholder.
+	hole func(verb rune) (AbstractString, bool)
+	// ints resolves the integer arguments of a modeled call. Nil means ValueToIntsWithOptions.
+	ints func(v ssa.Value) ([]int, bool)
+	// enumerate makes a modeled call resolved once for each combination of the values of its arguments,
+	// so that each result is derived only from the values it is made of instead of all of them.
+	enumerate bool
+}
+
+func (f *stringFlattener[T]) flatten(v ssa.Value, next func(v ssa.Value) ([]T, bool)) ([]T, bool) {
+	switch t := v.(type) {
+	case *ssa.BinOp:
+		if t.Op != token.ADD || !isString(t.Type()) {
+			break
+		}
+		x, xok := next(t.X)
+		y, yok := next(t.Y)
+		if !xok || !yok || len(x) == 0 || len(y) == 0 {
+			break
+		}
+		res := make([]T, 0, len(x)*len(y))
 		for _, xx := range x {
 			for _, yy := range y {
-				res = append(res, xx+yy)
+				res = append(res, f.derive(f.str(xx)+f.str(yy), xx, yy))
 			}
 		}
 		return res, true
+	case *ssa.Call:
+		if f.enumerate {
+			return f.modelEach(t, next)
+		}
+		return f.model(t, next)
+	case *ssa.Slice:
+		// e.g.
+		// s := "hello"
+		// s[:len(s)-1]
+		xs, ok := next(t.X)
+		if !ok {
+			break
+		}
+		res := make([]T, 0, len(xs))
+		for _, x := range xs {
+			for _, s := range sliceString(t, f.str(x), f.opts) {
+				res = append(res, f.derive(s, x))
+			}
+		}
+		return res, len(res) > 0
 	}
-	return []string{}, false
+	return []T{}, false
 }
 
-var fmtVerbRegexp = regexp.MustCompile(`(^|[^%]|(?:%%)+)(%(?:-?\d+|\+|#)?)(\w)`)
-
-// fmtSprintfToStrings returns the possible string values of fmt.Sprintf.
-func fmtSprintfToStrings(t *ssa.Call, fn func(v ssa.Value) ([]string, bool)) ([]string, bool) {
-	fs, ok := fn(t.Call.Args[0])
-	if !ok && len(fs) == 1 {
-		return []string{}, false
+// model resolves the modeled call once with all the values of the arguments, from which each result is derived.
+func (f *stringFlattener[T]) model(call *ssa.Call, next func(v ssa.Value) ([]T, bool)) ([]T, bool) {
+	var from []T
+	strs, ok := f.modelStrings(call, func(ts []T) []T {
+		from = append(from, ts...)
+		return ts
+	}, next)
+	if !ok {
+		return []T{}, false
+	}
+	res := make([]T, 0, len(strs))
+	for _, s := range strs {
+		res = append(res, f.derive(s, from...))
 	}
-	f := fmtVerbRegexp.ReplaceAllStringFunc(fs[0], func(s string) string {
-		m := fmtVerbRegexp.FindAllStringSubmatch(s, 1)
-		if m == nil || len(m) < 1 || len(m[0]) < 4 {
-			return s
+	return res, true
+}
+
+// modelEach resolves the modeled call once for each combination of the values of the arguments that
+// the model resolves, and derives each result from the argument values it is made of.
+func (f *stringFlattener[T]) modelEach(call *ssa.Call, next func(v ssa.Value) ([]T, bool)) ([]T, bool) {
+	// choices are the indices of the values chosen for the arguments, in the order that the model resolves them
+	var choices, counts []int
+	res := make([]T, 0)
+	for run := 0; run < maxModelRuns; run++ {
+		var from []T
+		counts = counts[:0]
+		strs, ok := f.modelStrings(call, func(ts []T) []T {
+			k := len(counts)
+			if k == len(choices) {
+				choices = append(choices, 0)
+			}
+			t := ts[min(choices[k], len(ts)-1)]
+			counts = append(counts, len(ts))
+			from = append(from, t)
+			return []T{t}
+		}, next)
+		if !ok {
+			return []T{}, false
 		}
-		switch m[0][3] {
-		case "b":
-			return m[0][1] + "01"
-		case "c":
-			return m[0][1] + "a"
-		case "t":
-			return m[0][1] + "true"
-		case "T":
-			return m[0][1] + "string"
-		case "e":
-			return m[0][1] + "1.234000e+08"
-		case "E":
-			return m[0][1] + "1.234000E+08"
-		case "p":
-			return m[0][1] + "0xc0000ba000"
-		case "x":
-			return m[0][1] + "1f"
-		case "d":
-			return m[0][1] + "1"
-		case "f":
-			return m[0][1] + "1.0"
-		default:
-			return s
+		for _, s := range strs {
+			res = append(res, f.derive(s, from...))
+		}
+
+		// advance to the next combination
+		choices = choices[:len(counts)]
+		i := len(choices) - 1
+		for ; i >= 0; i-- {
+			if choices[i]++; choices[i] < counts[i] {
+				break
+			}
+			choices[i] = 0
+		}
+		if i < 0 {
+			return res, true
 		}
-	})
-	if !fmtVerbRegexp.MatchString(f) { // no more verbs
-		return []string{f}, true
 	}
-	return []string{}, false
+	return []T{}, false
 }
 
-// stringsJoinToStrings returns the possible string values of strings.Join.
-func stringsJoinToStrings(t *ssa.Call, fn func(v ssa.Value) ([]string, bool)) ([]string, bool) {
-	// strings.Join
-	joiner, ok := fn(t.Call.Args[1])
-	if !ok || len(joiner) != 1 {
-		return []string{}, false
+// modelStrings returns the strings of the modeled call, where the values of each string argument resolved
+// by the model are chosen by choose.
+func (f *stringFlattener[T]) modelStrings(call *ssa.Call, choose func(ts []T) []T, next func(v ssa.Value) ([]T, bool)) ([]string, bool) {
+	hole, ints := f.hole, f.ints
+	if hole == nil {
+		hole = fmtPlaceholder
 	}
-	firstArg := t.Call.Args[0]
-	astArgs := make([]string, 0)
-	ast.Inspect(t.Parent().Syntax(), func(n ast.Node) bool {
-		if n == nil {
-			return false
-		}
-		if cl, ok := n.(*ast.CompositeLit); ok && n.Pos() <= firstArg.Pos() && firstArg.Pos() < n.End() {
-			for _, elt := range cl.Elts {
-				if bl, ok := elt.(*ast.BasicLit); ok {
-					if unquoted, err := Unquote(bl.Value); err == nil {
-						astArgs = append(astArgs, unquoted)
-					}
-				}
+	if ints == nil {
+		ints = func(v ssa.Value) ([]int, bool) { return ValueToIntsWithOptions(v, crossOptions(f.opts)) }
+	}
+	ctx := &ModelContext{call: call, opts: f.opts, hole: hole, ints: ints,
+		strs: func(v ssa.Value) ([]AbstractString, bool) {
+			ts, ok := next(v)
+			if !ok || len(ts) == 0 {
+				return nil, false
 			}
-			if len(astArgs) != len(cl.Elts) {
-				// not all elements are constant or some elements are failed to unquote
-				astArgs = []string{}
+			ts = choose(ts)
+			res := make([]AbstractString, 0, len(ts))
+			for _, t := range ts {
+				res = append(res, LiteralString(f.str(t)))
 			}
-			return false
+			return res, true
+		}}
+	as, ok := modelsOf(f.opts).stringsOf(GetCallInfo(call.Common()), ctx)
+	if !ok {
+		return []string{}, false
+	}
+	return constStrings(as)
+}
+
+// constStrings returns the strings of as, or false if any of them has a hole.
+func constStrings(as []AbstractString) ([]string, bool) {
+	res := make([]string, 0, len(as))
+	for _, a := range as {
+		if !a.IsConst() {
+			return []string{}, false
 		}
-		return true
-	})
-	if len(astArgs) > 0 {
-		return []string{strings.Join(astArgs, joiner[0])}, true
+		res = append(res, a.String())
 	}
-	return []string{}, false
+	return res, true
 }
 
 func Unquote(str string) (string, error) {
@@ -264,8 +298,31 @@ func Unquote(str string) (string, error) {
 	return str, nil
 }
 
-func stringIndex(v ssa.Value, ref ssa.Value, strLen int, maxDepth int) ([]int, bool) {
-	if i, ok := ValueToIntsWithMaxDepth(v, maxDepth); ok {
+// sliceString returns the possible results of the slice expression t of the string s.
+func sliceString(t *ssa.Slice, s string, opts ResolveOptions) []string {
+	l, lok := []int{0}, true
+	h, hok := []int{len(s)}, true
+	if t.Low != nil {
+		l, lok = stringIndex(t.Low, t.X, len(s), opts)
+	}
+	if t.High != nil {
+		h, hok = stringIndex(t.High, t.X, len(s), opts)
+	}
+	res := make([]string, 0)
+	if lok && hok {
+		for _, ll := range l {
+			for _, hh := range h {
+				if 0 <= ll && ll <= hh && hh <= len(s) {
+					res = append(res, s[ll:hh])
+				}
+			}
+		}
+	}
+	return res
+}
+
+func stringIndex(v ssa.Value, ref ssa.Value, strLen int, opts ResolveOptions) ([]int, bool) {
+	if i, ok := ValueToIntsWithOptions(v, opts); ok {
 		return i, true
 	}
 
@@ -273,7 +330,7 @@ func stringIndex(v ssa.Value, ref ssa.Value, strLen int, maxDepth int) ([]int, b
 		if call, ok := binOp.X.(*ssa.Call); ok {
 			c := GetCallInfo(call.Common())
 			if c.Name() == "len" && c.Arg(0) == ref {
-				if y, ok := ValueToIntsWithMaxDepth(binOp.Y, maxDepth); ok {
+				if y, ok := ValueToIntsWithOptions(binOp.Y, opts); ok {
 					res := make([]int, 0, len(y))
 					for _, yy := range y {
 						res = append(res, strLen-yy)
@@ -0,0 +1,608 @@
+package ssautil_test
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haijima/analysisutil/ssautil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/ssa"
+)
+
+func TestValueToStrings(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	got := make(map[string][][]string)
+	intra := make(map[string]bool)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			c := ssautil.GetCallInfo(call.Common())
+			if !c.Match("(*database/sql.DB).Query") {
+				continue
+			}
+			query, _ := c.ArgOK(0)
+			strs, ok := ssautil.ValueToStringsWithOptions(query, ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 3})
+			if !ok {
+				strs = nil
+			}
+			got[call.Parent().Name()] = append(got[call.Parent().Name()], strs)
+			_, ok = ssautil.ValueToStrings(query)
+			intra[call.Parent().Name()] = ok
+		}
+	}
+
+	want := map[string][][]string{
+		"constant":        {{"SELECT * FROM users"}},
+		"global":          {{"DELETE FROM users"}},
+		"globalField":     {{"INSERT INTO users"}, {"UPDATE users SET name = ?"}},
+		"localField":      {{"SELECT name FROM users"}},
+		"pointerField":    {{"SELECT id FROM users"}},
+		"reassignedField": {{"SELECT * FROM members", "SELECT * FROM admins"}},
+		"paramField":      {nil}, // the callers may store to the field through the pointer
+		"escapedLocal":    {nil}, // setQuery stores through the address of the variable
+		"escapedGlobal":   {nil},
+		"sprintf":         {{"SELECT * FROM users WHERE id = ?"}},
+		"branches":        {{"SELECT * FROM admins", "SELECT * FROM users", "SELECT * FROM users LIMIT 10"}},
+		"branchesConcat":  {{"SELECT * FROM users ORDER BY id", "SELECT * FROM users ORDER BY id DESC"}},
+		"limit":           {{"SELECT * FROM users LIMIT 1"}}, // a sample of an unknown integer
+		"limitBranch":     {{"SELECT * FROM users LIMIT 10"}},
+		"capturedVar":     {{"SELECT 1", "SELECT 2"}},
+		"helper":          {{"SELECT * FROM users"}, {"SELECT * FROM posts LIMIT 1"}},
+		"queryParam":      {{"SELECT 3", "SELECT 4"}},
+		"tuple":           {{"SELECT 5"}},
+		"abstract":        {nil},
+		"builder":         {{"SELECT * FROM users ORDER BY id DESC", "SELECT * FROM users ORDER BY id"}},
+		"builderLoop":     {nil}, // the loop may run any number of times
+		"builderCounted":  {{"SELECT 1"}},
+		"builderByte":     {nil},
+		"builderUnknown":  {nil},
+	}
+	assert.Equal(t, want, got)
+
+	// ValueToStrings does not follow calls
+	for _, fn := range []string{"helper", "queryParam", "tuple"} {
+		assert.False(t, intra[fn], fn)
+	}
+	assert.True(t, intra["reassignedField"])
+}
+
+func TestValueToStringsWithOptions(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	queries := make(map[string]ssa.Value)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			if c := ssautil.GetCallInfo(call.Common()); c.Match("(*database/sql.DB).Query") {
+				queries[call.Parent().Name()], _ = c.ArgOK(0) // the last query of each function
+			}
+		}
+	}
+
+	tests := []struct {
+		fn   string
+		opts ssautil.ResolveOptions
+		want []string
+	}{
+		{"helper", ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 2}, []string{"SELECT * FROM posts LIMIT 1"}},
+		{"helper", ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 1}, nil},
+		{"helper", ssautil.ResolveOptions{MaxDepth: 2, MaxCallDepth: 3}, nil},
+		{"queryParam", ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 1}, []string{"SELECT 3", "SELECT 4"}},
+		{"queryParam", ssautil.ResolveOptions{MaxDepth: 10}, nil},
+		{"global", ssautil.ResolveOptions{MaxDepth: 10}, []string{"DELETE FROM users"}},
+	}
+	for _, tt := range tests {
+		got, ok := ssautil.ValueToStringsWithOptions(queries[tt.fn], tt.opts)
+		assert.Equal(t, tt.want != nil, ok, "%s %+v", tt.fn, tt.opts)
+		if tt.want != nil {
+			assert.Equal(t, tt.want, got, "%s %+v", tt.fn, tt.opts)
+		}
+	}
+}
+
+func TestValueToAbstractStrings(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	queries := make(map[string]ssa.Value)
+	execs := make(map[string]ssa.Value)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			if c := ssautil.GetCallInfo(call.Common()); c.Match("(*database/sql.DB).Query") {
+				queries[call.Parent().Name()], _ = c.ArgOK(0)
+			} else if c.Match("(*database/sql.DB).Exec") {
+				execs[call.Parent().Name()], _ = c.ArgOK(0)
+			}
+		}
+	}
+
+	as, ok := ssautil.ValueToAbstractStrings(queries["abstract"])
+	require.True(t, ok)
+	require.Equal(t, 1, len(as))
+	a := as[0]
+	assert.Equal(t, ssautil.AbstractString{
+		{Literal: "SELECT * FROM "}, {Hole: true}, {Literal: " WHERE "}, {Hole: true}, {Literal: " = ?"},
+	}, a)
+	assert.False(t, a.IsConst())
+	assert.Equal(t, "SELECT * FROM <?> WHERE <?> = ?", a.String())
+	assert.Equal(t, "SELECT \\* FROM * WHERE * = \\?", a.Glob())
+	assert.Equal(t, `^SELECT \* FROM (?s:.*) WHERE (?s:.*) = \?$`, a.Regexp().String())
+	assert.True(t, a.Match("SELECT * FROM users WHERE id = ?"))
+	assert.False(t, a.Match("SELECT id FROM users WHERE id = ?"))
+	assert.Equal(t, "SELECT * FROM ", a.Prefix())
+	assert.Equal(t, " = ?", a.Suffix())
+
+	as, ok = ssautil.ValueToAbstractStrings(queries["builderUnknown"])
+	require.True(t, ok)
+	assert.Equal(t, []ssautil.AbstractString{{{Literal: "SELECT * FROM "}, {Hole: true}, {Literal: ";"}}}, as)
+
+	as, ok = ssautil.ValueToAbstractStrings(queries["builderCounted"])
+	require.True(t, ok)
+	assert.Equal(t, []ssautil.AbstractString{ssautil.LiteralString("SELECT 1")}, as)
+	as, ok = ssautil.ValueToAbstractStrings(queries["builderByte"])
+	require.True(t, ok)
+	assert.Equal(t, []ssautil.AbstractString{{{Literal: "SELECT "}, {Hole: true}}}, as)
+
+	as, ok = ssautil.ValueToAbstractStrings(queries["builderLoop"])
+	require.True(t, ok)
+	assert.ElementsMatch(t, []ssautil.AbstractString{
+		ssautil.LiteralString("SELECT 1"), ssautil.LiteralString("SELECT 1, 1"), ssautil.LiteralString("SELECT 1, 1, 1"),
+		{{Literal: "SELECT 1, 1, 1, 1"}, {Hole: true}},
+	}, as)
+
+	as, ok = ssautil.ValueToAbstractStrings(queries["reassignedField"])
+	require.True(t, ok)
+	assert.Equal(t, []ssautil.AbstractString{ssautil.LiteralString("SELECT * FROM members"), ssautil.LiteralString("SELECT * FROM admins")}, as)
+	assert.True(t, as[0].IsConst())
+
+	// the parameter table has no callers, so its edge of the merge is a hole
+	as, ok = ssautil.ValueToAbstractStrings(execs["branchesConcat"])
+	require.True(t, ok)
+	assert.ElementsMatch(t, []ssautil.AbstractString{{{Literal: "DELETE FROM "}, {Hole: true}}, ssautil.LiteralString("DELETE FROM users")}, as)
+	opts := ssautil.DefaultResolveOptions()
+	opts.Strict = true
+	as2, ok := ssautil.ValueToAbstractStringsWithOptions(execs["branchesConcat"], opts)
+	require.True(t, ok)
+	assert.Equal(t, as, as2)
+
+	assert.Equal(t, ssautil.AbstractString{{Literal: "ab"}, {Hole: true}},
+		ssautil.LiteralString("a").Concat(ssautil.LiteralString("b")).Concat(ssautil.UnknownString()).Concat(ssautil.UnknownString()))
+}
+
+func TestValueToStrings_Fmt(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	calls := make([]ssa.Value, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "formats" {
+			calls = append(calls, call)
+		}
+	}
+
+	tests := []struct {
+		want     []string
+		abstract string
+	}{
+		{[]string{"SELECT * FROM users WHERE id = 1"}, "SELECT * FROM users WHERE id = 1"},
+		{[]string{`SELECT "users"`}, `SELECT "users"`},
+		{[]string{" 3.14|7   |ff|true"}, " 3.14|7   |ff|true"},
+		{[]string{"LIMIT 1"}, "LIMIT <?>"},
+		{nil, "FROM <?>"},
+		{[]string{"SELECT 1 2"}, "SELECT 1 2"},
+		{[]string{"SELECT 1\n"}, "SELECT 1\n"},
+		{[]string{"no rows in users"}, "no rows in users"},
+		{[]string{"50%"}, "50%"},
+		{[]string{"1 %!d(MISSING)"}, "1 %!d(MISSING)"},
+	}
+	require.Equal(t, len(tests), len(calls))
+	for i, tt := range tests {
+		got, ok := ssautil.ValueToStrings(calls[i])
+		assert.Equal(t, tt.want != nil, ok, "%d", i)
+		if tt.want != nil {
+			assert.Equal(t, tt.want, got, "%d", i)
+		}
+
+		as, ok := ssautil.ValueToAbstractStrings(calls[i])
+		assert.True(t, ok, "%d", i)
+		if assert.Equal(t, 1, len(as), "%d", i) {
+			assert.Equal(t, tt.abstract, as[0].String(), "%d", i)
+		}
+	}
+}
+
+func TestValueToStrings_Models(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	calls := make([]ssa.Value, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "models" {
+			calls = append(calls, call)
+		}
+	}
+	require.Equal(t, 5, len(calls))
+
+	as, ok := ssautil.ValueToAbstractStrings(calls[0])
+	assert.True(t, ok)
+	assert.Equal(t, []ssautil.AbstractString{{{Literal: "SELECT * FROM "}, {Hole: true}}}, as)
+	for i, want := range map[int]string{1: "6", 2: "a/c", 3: "a_b_c"} {
+		got, ok := ssautil.ValueToStrings(calls[i])
+		assert.True(t, ok, "%d", i)
+		assert.Equal(t, []string{want}, got, "%d", i)
+	}
+	_, ok = ssautil.ValueToStrings(calls[4])
+	assert.False(t, ok)
+
+	models := ssautil.DefaultModels()
+	require.NoError(t, models.AddString("github.com/haijima/analysisutil/ssautil/testdata/src/value.tableName", func(c ssautil.CallInfo, ctx *ssautil.ModelContext) ([]ssautil.AbstractString, bool) {
+		kinds, ok := ctx.Ints(c.Arg(0))
+		if !ok {
+			return nil, false
+		}
+		res := make([]ssautil.AbstractString, 0, len(kinds))
+		for _, k := range kinds {
+			res = append(res, ssautil.LiteralString(fmt.Sprintf("table%d", k)))
+		}
+		return res, true
+	}))
+	require.NoError(t, models.AddString("strings.ReplaceAll", func(c ssautil.CallInfo, ctx *ssautil.ModelContext) ([]ssautil.AbstractString, bool) {
+		return []ssautil.AbstractString{ssautil.LiteralString("overridden")}, true
+	}))
+	require.Error(t, models.AddInt("(", nil))
+	opts := ssautil.DefaultResolveOptions()
+	opts.Models = models
+
+	got, ok := ssautil.ValueToStringsWithOptions(calls[4], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"table2"}, got)
+	got, ok = ssautil.ValueToStringsWithOptions(calls[3], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"overridden"}, got)
+	_, ok = ssautil.ValueToStrings(calls[4]) // the default models are not affected
+	assert.False(t, ok)
+}
+
+func TestValueToIntRange(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	args := make([]ssa.Value, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "ints" {
+			if c := ssautil.GetCallInfo(call.Common()); c.Match("*.size") {
+				args = append(args, c.Arg(0))
+			}
+		}
+	}
+
+	tests := []struct {
+		ints []int
+		r    ssautil.IntRange
+		ok   bool
+	}{
+		{[]int{2}, ssautil.IntRange{Min: 2, Max: 2}, true},
+		{[]int{0x10d}, ssautil.IntRange{Min: 0x10d, Max: 0x10d}, true},
+		{[]int{0x100}, ssautil.IntRange{Min: 0x100, Max: 0x100}, true},
+		{nil, ssautil.IntRange{Min: 0, Max: 0xff}, true},
+		{nil, ssautil.IntRange{Min: 0, Max: 0xff}, true},
+		{nil, ssautil.IntRange{Min: 44, Max: 44}, true},
+		{nil, ssautil.IntRange{Min: -256, Max: 254}, true},
+		{nil, ssautil.IntRange{Min: -9, Max: 9}, true},
+		{nil, ssautil.IntRange{Min: 0, Max: 100}, true},
+		{nil, ssautil.IntRange{Min: -3, Max: 10}, true},
+		{nil, ssautil.IntRange{Min: 0, Max: 15}, true},
+		{nil, ssautil.IntRange{Min: 0, Max: 255}, true},
+		{nil, ssautil.IntRange{Min: math.MinInt64, Max: math.MaxInt64}, false},
+		{nil, ssautil.IntRange{Min: math.MinInt64, Max: math.MaxInt64}, false}, // a uint64 may not fit in int64
+		{nil, ssautil.IntRange{Min: 0, Max: 0xff}, true},
+		{nil, ssautil.IntRange{Min: math.MinInt64, Max: math.MaxInt64}, false}, // a negative shift count panics
+	}
+	require.Equal(t, len(tests), len(args))
+	for i, tt := range tests {
+		ints, ok := ssautil.ValueToInts(args[i])
+		assert.Equal(t, tt.ints != nil, ok, "%d", i)
+		if tt.ints != nil {
+			assert.Equal(t, tt.ints, ints, "%d", i)
+		}
+
+		r, ok := ssautil.ValueToIntRange(args[i])
+		assert.Equal(t, tt.ok, ok, "%d", i)
+		assert.Equal(t, tt.r, r, "%d %s", i, r)
+	}
+}
+
+func TestValueToConstValues(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	args := make(map[string][]ssa.Value)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "typedConsts" {
+			if fn := call.Common().StaticCallee(); fn != nil && len(fn.Params) == 1 {
+				args[fn.Name()] = append(args[fn.Name()], call.Common().Args[0])
+			}
+		}
+	}
+
+	opts := ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 1} // follow the calls of num
+	durations, ok := ssautil.ValueToDurationsWithOptions(args["timeout"][0], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []time.Duration{5 * time.Second}, durations)
+	durations, ok = ssautil.ValueToDurationsWithOptions(args["timeout"][1], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []time.Duration{3 * time.Millisecond}, durations)
+	_, ok = ssautil.ValueToDurations(args["timeout"][1]) // calls are not followed by default
+	assert.False(t, ok)
+	_, ok = ssautil.ValueToDurationsWithOptions(args["level"][0], opts) // not a time.Duration
+	assert.False(t, ok)
+
+	floats, ok := ssautil.ValueToFloatsWithOptions(args["ratio"][0], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []float64{float64(float32(1) / 3)}, floats)
+
+	for _, arg := range args["enabled"] {
+		bools, ok := ssautil.ValueToBoolsWithOptions(arg, opts)
+		if assert.True(t, ok) {
+			assert.Equal(t, []bool{true}, bools)
+		}
+	}
+
+	bigInts, ok := ssautil.ValueToBigIntsWithOptions(args["mask"][0], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []*big.Int{new(big.Int).SetUint64(math.MaxUint64)}, bigInts)
+	_, ok = ssautil.ValueToIntsWithOptions(args["mask"][0], opts)
+	assert.False(t, ok)
+
+	named, ok := ssautil.ValueToNamedConstsWithOptions(args["level"][0], opts)
+	assert.True(t, ok)
+	require.Equal(t, 1, len(named))
+	assert.Equal(t, "main.Info", named[0].Name())
+	named, ok = ssautil.ValueToNamedConstsWithOptions(args["level"][1], opts)
+	assert.True(t, ok)
+	names := make([]string, 0, len(named))
+	for _, n := range named {
+		names = append(names, n.Name())
+	}
+	assert.ElementsMatch(t, []string{"main.Debug", "3"}, names)
+
+	complexes, ok := ssautil.ValueToComplexesWithOptions(args["impedance"][0], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []complex128{1 + 2i}, complexes)
+	complexes, ok = ssautil.ValueToComplexesWithOptions(args["impedance"][1], opts)
+	assert.True(t, ok)
+	assert.Equal(t, []complex128{2 + 1i}, complexes)
+}
+
+func TestValueToStringsTraced(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	queries := make(map[string]ssa.Value)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			if c := ssautil.GetCallInfo(call.Common()); c.Match("(*database/sql.DB).Query") {
+				queries[call.Parent().Name()], _ = c.ArgOK(0)
+			}
+		}
+	}
+	src, err := os.ReadFile("./testdata/src/value/main.go")
+	require.NoError(t, err)
+	lineOf := func(lit string) int {
+		for i, line := range strings.Split(string(src), "\n") {
+			if strings.Contains(line, lit) {
+				return i + 1
+			}
+		}
+		return 0
+	}
+
+	tests := []struct {
+		fn       string
+		value    string
+		literals []string
+	}{
+		{"reassignedField", "SELECT * FROM members", []string{`"SELECT * FROM members"`}},
+		{"reassignedField", "SELECT * FROM admins", []string{`"SELECT * FROM admins"`}},
+		{"helper", "SELECT * FROM posts LIMIT 1", []string{`"SELECT * FROM " + table`, `buildLimitedQuery("posts")`, `" LIMIT 1"`}},
+		{"sprintf", "SELECT * FROM users WHERE id = ?", []string{`"SELECT * FROM %s WHERE id = ?"`, `"users")) // table`}},
+	}
+	for _, tt := range tests {
+		traced, ok := ssautil.ValueToStringsTracedWithOptions(queries[tt.fn], ssautil.ResolveOptions{MaxDepth: 10, MaxCallDepth: 3})
+		require.True(t, ok, tt.fn)
+		i := slices.IndexFunc(traced, func(tr ssautil.Traced[string]) bool { return tr.Value == tt.value })
+		require.GreaterOrEqual(t, i, 0, tt.value)
+
+		lines := make([]int, 0)
+		for _, o := range traced[i].Literals() {
+			lines = append(lines, o.Pos.Position().Line)
+		}
+		want := make([]int, 0, len(tt.literals))
+		for _, lit := range tt.literals {
+			want = append(want, lineOf(lit))
+		}
+		assert.Equal(t, want, lines, tt.value)
+	}
+
+	traced, ok := ssautil.ValueToStringsTraced(queries["reassignedField"])
+	require.True(t, ok)
+	phi := slices.IndexFunc(traced[1].Path, func(o ssautil.Origin) bool { _, ok := o.Value.(*ssa.Phi); return ok })
+	assert.Equal(t, -1, phi) // fields are followed to their stores, not through phis
+	s, ok := ssautil.ValueToStrings(queries["reassignedField"])
+	assert.True(t, ok)
+	assert.Equal(t, []string{traced[0].Value, traced[1].Value}, s)
+}
+
+func TestValueToStringsGuarded(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	args := make(map[string]ssa.Value)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok {
+			c := ssautil.GetCallInfo(call.Common())
+			if c.Match("(*database/sql.DB).Query") {
+				args[call.Parent().Name()+".Query"], _ = c.ArgOK(0)
+			} else if c.Match("(*database/sql.DB).Exec") {
+				args[call.Parent().Name()+".Exec"], _ = c.ArgOK(0)
+			}
+		}
+	}
+	// conds formats the conditions as the names of their values, negated if the branch is not taken.
+	conds := func(g ssautil.Guarded[string]) []string {
+		res := make([]string, 0, len(g.Conds))
+		for _, c := range g.Conds {
+			name := c.If.Cond.Name()
+			if bin, ok := c.If.Cond.(*ssa.BinOp); ok {
+				name = bin.X.Name() + " " + bin.Op.String() + " " + bin.Y.Name()
+			}
+			if !c.Value {
+				name = "!(" + name + ")"
+			}
+			res = append(res, name)
+		}
+		return res
+	}
+	guarded := func(r ssautil.Resolution[string]) map[string][]string {
+		res := make(map[string][]string)
+		for _, g := range r.Values {
+			res[g.Value] = conds(g)
+		}
+		return res
+	}
+
+	r, ok := ssautil.ValueToStringsGuarded(args["branches.Query"])
+	require.True(t, ok)
+	assert.True(t, r.Complete)
+	assert.Equal(t, map[string][]string{
+		"SELECT * FROM admins":         {"admin"},
+		"SELECT * FROM users":          {"!(admin)", "!(n > 10:int)"},
+		"SELECT * FROM users LIMIT 10": {"!(admin)", "n > 10:int"},
+	}, guarded(r))
+
+	r, ok = ssautil.ValueToStringsGuarded(args["branchesConcat.Query"])
+	require.True(t, ok)
+	assert.True(t, r.Complete)
+	assert.Equal(t, map[string][]string{
+		"SELECT * FROM users ORDER BY id":      {"!(desc)"},
+		"SELECT * FROM users ORDER BY id DESC": {"desc"},
+	}, guarded(r))
+
+	// the parameter table has no call sites
+	r, ok = ssautil.ValueToStringsGuarded(args["branchesConcat.Exec"])
+	require.True(t, ok)
+	assert.False(t, r.Complete)
+	assert.Equal(t, map[string][]string{"DELETE FROM users": {`table == "":string`}}, guarded(r))
+
+	opts := ssautil.DefaultResolveOptions()
+	opts.Strict = true
+	_, ok = ssautil.ValueToStringsGuardedWithOptions(args["branchesConcat.Exec"], opts)
+	assert.False(t, ok)
+	_, ok = ssautil.ValueToStringsWithOptions(args["branchesConcat.Exec"], opts)
+	assert.False(t, ok)
+	strs, ok := ssautil.ValueToStrings(args["branchesConcat.Exec"])
+	assert.True(t, ok)
+	assert.Equal(t, []string{"DELETE FROM users"}, strs)
+	r, ok = ssautil.ValueToStringsGuardedWithOptions(args["branches.Query"], opts)
+	assert.True(t, ok)
+	assert.Len(t, r.Values, 3)
+
+	// an unknown operand of fmt.Sprintf is a sample value, and the dropped edge of an argument is incomplete
+	for _, fn := range []string{"limit", "limitBranch"} {
+		r, ok = ssautil.ValueToStringsGuarded(args[fn+".Query"])
+		require.True(t, ok, fn)
+		assert.False(t, r.Complete, fn)
+		_, ok = ssautil.ValueToStringsGuardedWithOptions(args[fn+".Query"], opts)
+		assert.False(t, ok, fn)
+	}
+}
+
+func TestValueToElems(t *testing.T) {
+	instrs, err := GetInstructions(t, "./testdata/src/value", "./...")
+	require.NoError(t, err)
+
+	args := make(map[string][]ssa.Value)
+	joins := make([]ssa.Value, 0)
+	for _, instr := range instrs {
+		if call, ok := instr.(*ssa.Call); ok && call.Parent().Name() == "collections" {
+			if fn := call.Common().StaticCallee(); fn != nil && fn.Pkg != nil && fn.Pkg.Pkg.Name() == "main" {
+				args[fn.Name()] = append(args[fn.Name()], call.Common().Args[0])
+			} else if ssautil.GetCallInfo(call.Common()).Match("strings.Join") {
+				joins = append(joins, call)
+			}
+		}
+	}
+
+	// allowTable stores to an element of the global
+	strs, ok := ssautil.ValueToStringSlices(args["tables"][0])
+	assert.True(t, ok)
+	assert.Equal(t, [][]string{{"users", "posts"}, {"users", "comments"}}, strs)
+	strs, ok = ssautil.ValueToStringSlices(args["tables"][1])
+	assert.True(t, ok)
+	assert.Equal(t, [][]string{{"posts", "comments", "users"}, {"comments", "comments", "users"}}, strs)
+	_, ok = ssautil.ValueToStringSlices(args["tables"][2])
+	assert.False(t, ok) // the parameter names has no call sites
+	_, ok = ssautil.ValueToStringSlices(args["tables"][3])
+	assert.False(t, ok) // an element is overwritten through the slice
+	strs, ok = ssautil.ValueToStringSlices(args["tables"][4])
+	assert.True(t, ok)
+	assert.Equal(t, [][]string{{"a", "b"}, {"a", ""}}, strs) // the second element may not be stored
+	_, ok = ssautil.ValueToStringSlices(args["tables"][5])
+	assert.False(t, ok) // appended in a loop
+
+	ints, ok := ssautil.ValueToIntSlices(args["ports"][0])
+	assert.True(t, ok)
+	assert.Equal(t, [][]int{{80, 443}}, ints)
+	ints, ok = ssautil.ValueToIntSlices(args["ports"][1])
+	assert.True(t, ok)
+	assert.Equal(t, [][]int{{8080, 0, 8443}}, ints)
+
+	levels, ok := ssautil.ValueToElemsWithOptions(args["levels"][0], ssautil.DefaultResolveOptions(), ssautil.ValueToNamedConsts)
+	require.True(t, ok)
+	require.Len(t, levels, 1)
+	names := make([]string, 0)
+	for _, l := range levels[0] {
+		names = append(names, l.Name())
+	}
+	assert.Equal(t, []string{"main.Debug", "main.Warn"}, names)
+
+	_, ok = ssautil.ValueToStringMaps(args["router"][0])
+	assert.False(t, ok) // addRoute updates the global
+	maps, ok := ssautil.ValueToStringMaps(args["router"][1])
+	assert.True(t, ok)
+	assert.ElementsMatch(t, [][]ssautil.MapEntry[string, string]{
+		{{Key: "/", Value: "index"}},
+		{{Key: "/", Value: "index"}, {Key: "/debug", Value: "debug"}},
+	}, maps)
+	maps, ok = ssautil.ValueToStringMaps(args["router"][2])
+	assert.True(t, ok)
+	assert.ElementsMatch(t, [][]ssautil.MapEntry[string, string]{
+		{{Key: "/", Value: "index"}, {Key: "/debug", Value: "debug"}},
+		{{Key: "/debug", Value: "debug"}},
+	}, maps)
+	maps, ok = ssautil.ValueToStringMaps(args["router"][3])
+	assert.True(t, ok)
+	assert.ElementsMatch(t, [][]ssautil.MapEntry[string, string]{
+		{{Key: "/", Value: "index"}},
+		{{Key: "/", Value: "index"}, {Key: "/x", Value: "x"}},
+	}, maps)
+	maps, ok = ssautil.ValueToStringMaps(args["router"][4])
+	assert.True(t, ok)
+	assert.Equal(t, [][]ssautil.MapEntry[string, string]{{{Key: "a", Value: "2"}}}, maps)
+
+	require.Len(t, joins, 2)
+	joined, ok := ssautil.ValueToStrings(joins[0])
+	assert.True(t, ok)
+	assert.Equal(t, []string{"users, posts", "users, comments"}, joined)
+	joined, ok = ssautil.ValueToStrings(joins[1])
+	assert.True(t, ok)
+	assert.Equal(t, []string{"id,name,EMAIL"}, joined)
+}
@@ -0,0 +1,3 @@
+module github.com/haijima/analysisutil/testdata/src/load
+
+go 1.22.2
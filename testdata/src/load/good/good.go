@@ -0,0 +1,5 @@
+package good
+
+func Hello() string {
+	return "hello"
+}
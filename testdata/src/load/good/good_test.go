@@ -0,0 +1,9 @@
+package good
+
+import "testing"
+
+func TestHello(t *testing.T) {
+	if Hello() != "hello" {
+		t.Fail()
+	}
+}
@@ -0,0 +1,3 @@
+package platform
+
+const OS = "darwin"
@@ -0,0 +1,5 @@
+//go:build !extra
+
+package tagged
+
+const Extra = false
@@ -0,0 +1,7 @@
+package usesbroken
+
+import "github.com/haijima/analysisutil/testdata/src/load/broken"
+
+func UsesBroken() int {
+	return broken.Broken()
+}